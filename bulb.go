@@ -0,0 +1,168 @@
+package mystrom
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Bulb holds all info and logic to talk your myStrom Bulb device.
+type Bulb struct {
+	device deviceAPI
+}
+
+// NewBulb creates a new Bulb instance.
+func (c *Client) NewBulb(baseURL *url.URL) *Bulb {
+	return &Bulb{
+		device: deviceAPI{
+			baseURL: baseURL,
+			client:  c,
+		},
+	}
+}
+
+// NewBulb creates a new Bulb instance with a default client.
+func NewBulb(baseURL *url.URL) *Bulb {
+	return NewClient().NewBulb(baseURL)
+}
+
+// BulbState represents the state of a Bulb.
+type BulbState struct {
+	MAC             string    `json:"-"`
+	Type            string    `json:"type"`
+	Battery         bool      `json:"battery"`
+	Reachable       bool      `json:"reachable"`
+	MeshRoot        bool      `json:"meshroot"`
+	On              bool      `json:"on"`
+	Color           string    `json:"color"` // format depends on Mode
+	Mode            ColorMode `json:"mode"`
+	Ramp            int       `json:"ramp"`  // transition time in milliseconds
+	Power           float64   `json:"power"` // current power consumption in watts
+	FirmwareVersion string    `json:"fw_version"`
+}
+
+// HSV returns the color of the Bulb if it is in HSV mode.
+func (s BulbState) HSV() (HSV, error) {
+	if s.Mode != ColorModeHSV {
+		return HSV{}, fmt.Errorf("bulb is in %s mode", s.Mode)
+	}
+	return parseHSV(s.Color)
+}
+
+// RGBW returns the color of the Bulb if it is in RGB mode.
+func (s BulbState) RGBW() (RGBW, error) {
+	if s.Mode != ColorModeRGB {
+		return RGBW{}, fmt.Errorf("bulb is in %s mode", s.Mode)
+	}
+	return parseRGBW(s.Color)
+}
+
+// Mono returns the color temperature and brightness of the Bulb if it is in mono mode.
+func (s BulbState) Mono() (Mono, error) {
+	if s.Mode != ColorModeMono {
+		return Mono{}, fmt.Errorf("bulb is in %s mode", s.Mode)
+	}
+	return parseMono(s.Color)
+}
+
+// RampTime returns the configured transition time.
+func (s BulbState) RampTime() time.Duration {
+	return time.Duration(s.Ramp) * time.Millisecond
+}
+
+// State returns the current state of the Bulb.
+func (b *Bulb) State(ctx context.Context) (*BulbState, error) {
+	state := BulbState{}
+
+	mac, err := b.device.state(ctx, &state)
+	state.MAC = mac
+	return &state, err
+}
+
+// On turns the Bulb on.
+func (b *Bulb) On(ctx context.Context) error {
+	return b.device.set(ctx, url.Values{"action": []string{"on"}})
+}
+
+// Off turns the Bulb off.
+func (b *Bulb) Off(ctx context.Context) error {
+	return b.device.set(ctx, url.Values{"action": []string{"off"}})
+}
+
+// Toggle toggles the power state of the Bulb.
+func (b *Bulb) Toggle(ctx context.Context) error {
+	return b.device.set(ctx, url.Values{"action": []string{"toggle"}})
+}
+
+// SetHSV sets the color of the Bulb in the HSV color space.
+func (b *Bulb) SetHSV(ctx context.Context, color HSV) error {
+	return b.device.set(ctx, url.Values{
+		"mode":  []string{string(ColorModeHSV)},
+		"color": []string{color.String()},
+	})
+}
+
+// SetRGBW sets the color of the Bulb using red, green, blue and white channels.
+func (b *Bulb) SetRGBW(ctx context.Context, color RGBW) error {
+	return b.device.set(ctx, url.Values{
+		"mode":  []string{string(ColorModeRGB)},
+		"color": []string{color.String()},
+	})
+}
+
+// SetMono sets the Bulb to white light with the given color temperature and brightness.
+func (b *Bulb) SetMono(ctx context.Context, mono Mono) error {
+	return b.device.set(ctx, url.Values{
+		"mode":  []string{string(ColorModeMono)},
+		"color": []string{mono.String()},
+	})
+}
+
+// SetBrightness changes the brightness (0 - 100) while keeping the current color.
+func (b *Bulb) SetBrightness(ctx context.Context, brightness int) error {
+	state, err := b.State(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch state.Mode {
+	case ColorModeMono:
+		mono, err := state.Mono()
+		if err != nil {
+			return err
+		}
+		mono.Brightness = brightness
+		return b.SetMono(ctx, mono)
+	case ColorModeHSV:
+		hsv, err := state.HSV()
+		if err != nil {
+			return err
+		}
+		hsv.Value = brightness
+		return b.SetHSV(ctx, hsv)
+	case ColorModeRGB:
+		rgbw, err := state.RGBW()
+		if err != nil {
+			return err
+		}
+		return b.SetRGBW(ctx, rgbw.withBrightness(brightness))
+	default:
+		return b.SetHSV(ctx, HSV{Value: brightness})
+	}
+}
+
+// SetRamp sets the transition time used for color and power changes.
+func (b *Bulb) SetRamp(ctx context.Context, ramp time.Duration) error {
+	return b.device.set(ctx, url.Values{"ramp": []string{strconv.Itoa(int(ramp.Milliseconds()))}})
+}
+
+// SetMode changes the color mode of the Bulb.
+func (b *Bulb) SetMode(ctx context.Context, mode ColorMode) error {
+	return b.device.set(ctx, url.Values{"mode": []string{string(mode)}})
+}
+
+func (b *Bulb) URL() url.URL {
+	return *b.device.baseURL
+}
@@ -0,0 +1,245 @@
+package mystrom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+const bulbState = `{"5CCF7F000001": {"type": "rgblamp", "battery": false, "reachable": true, "meshroot": true, "on": true, "color": "120;90;50", "mode": "hsv", "ramp": 100, "power": 4.5, "fw_version": "2.58.0"}}`
+
+func TestBulb_State(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		body       []byte
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *mystrom.BulbState
+		wantErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				body:       []byte(bulbState),
+				statusCode: http.StatusOK,
+			},
+			want: &mystrom.BulbState{
+				MAC:             "5CCF7F000001",
+				Type:            "rgblamp",
+				Reachable:       true,
+				MeshRoot:        true,
+				On:              true,
+				Color:           "120;90;50",
+				Mode:            mystrom.ColorModeHSV,
+				Ramp:            100,
+				Power:           4.5,
+				FirmwareVersion: "2.58.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty",
+			args: args{
+				body:       []byte(`{}`),
+				statusCode: http.StatusOK,
+			},
+			wantErr: true,
+		},
+		{
+			name: "error",
+			args: args{
+				statusCode: http.StatusInternalServerError,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/device" {
+					t.Errorf("expected /api/v1/device path, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.args.statusCode)
+				_, _ = w.Write(tt.args.body)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			b := client.NewBulb(baseURL)
+
+			state, err := b.State(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Bulb.State() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(state, tt.want) {
+				t.Errorf("Bulb.State() = %v, want %v", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestBulbState_Colors(t *testing.T) {
+	t.Parallel()
+
+	hsv, err := mystrom.BulbState{Mode: mystrom.ColorModeHSV, Color: "120;90;50"}.HSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (mystrom.HSV{Hue: 120, Saturation: 90, Value: 50}); hsv != want {
+		t.Errorf("BulbState.HSV() = %v, want %v", hsv, want)
+	}
+
+	rgbw, err := mystrom.BulbState{Mode: mystrom.ColorModeRGB, Color: "10FF8000"}.RGBW()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (mystrom.RGBW{R: 0xFF, G: 0x80, B: 0x00, W: 0x10}); rgbw != want {
+		t.Errorf("BulbState.RGBW() = %v, want %v", rgbw, want)
+	}
+
+	mono, err := mystrom.BulbState{Mode: mystrom.ColorModeMono, Color: "5;80"}.Mono()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (mystrom.Mono{Temperature: 5, Brightness: 80}); mono != want {
+		t.Errorf("BulbState.Mono() = %v, want %v", mono, want)
+	}
+
+	if _, err := (mystrom.BulbState{Mode: mystrom.ColorModeMono, Color: "5;80"}).HSV(); err == nil {
+		t.Error("BulbState.HSV() expected error for mono mode")
+	}
+}
+
+func TestBulb_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		state string // defaults to bulbState
+		call  func(*mystrom.Bulb, context.Context) error
+		want  url.Values
+	}{
+		{
+			name: "on",
+			call: (*mystrom.Bulb).On,
+			want: url.Values{"action": []string{"on"}},
+		},
+		{
+			name: "off",
+			call: (*mystrom.Bulb).Off,
+			want: url.Values{"action": []string{"off"}},
+		},
+		{
+			name: "toggle",
+			call: (*mystrom.Bulb).Toggle,
+			want: url.Values{"action": []string{"toggle"}},
+		},
+		{
+			name: "hsv",
+			call: func(b *mystrom.Bulb, ctx context.Context) error {
+				return b.SetHSV(ctx, mystrom.HSV{Hue: 360, Saturation: 100, Value: 10})
+			},
+			want: url.Values{"mode": []string{"hsv"}, "color": []string{"360;100;10"}},
+		},
+		{
+			name: "rgbw",
+			call: func(b *mystrom.Bulb, ctx context.Context) error {
+				return b.SetRGBW(ctx, mystrom.RGBW{R: 0xFF, W: 0x01})
+			},
+			want: url.Values{"mode": []string{"rgb"}, "color": []string{"01FF0000"}},
+		},
+		{
+			name: "mono",
+			call: func(b *mystrom.Bulb, ctx context.Context) error {
+				return b.SetMono(ctx, mystrom.Mono{Temperature: 18, Brightness: 30})
+			},
+			want: url.Values{"mode": []string{"mono"}, "color": []string{"18;30"}},
+		},
+		{
+			name: "brightness",
+			call: func(b *mystrom.Bulb, ctx context.Context) error {
+				return b.SetBrightness(ctx, 20)
+			},
+			want: url.Values{"mode": []string{"hsv"}, "color": []string{"120;90;20"}},
+		},
+		{
+			name:  "brightness rgb",
+			state: `{"5CCF7F000001": {"on": true, "color": "10FF8000", "mode": "rgb"}}`,
+			call: func(b *mystrom.Bulb, ctx context.Context) error {
+				return b.SetBrightness(ctx, 50)
+			},
+			want: url.Values{"mode": []string{"rgb"}, "color": []string{"077F3F00"}},
+		},
+		{
+			name: "ramp",
+			call: func(b *mystrom.Bulb, ctx context.Context) error {
+				return b.SetRamp(ctx, 2*time.Second)
+			},
+			want: url.Values{"ramp": []string{"2000"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && r.URL.Path == "/api/v1/device" {
+					state := tt.state
+					if state == "" {
+						state = bulbState
+					}
+					_, _ = w.Write([]byte(state))
+					return
+				}
+
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/device/5CCF7F000001" {
+					t.Errorf("expected /api/v1/device/5CCF7F000001 path, got %s", r.URL.Path)
+				}
+
+				if !reflect.DeepEqual(r.URL.Query(), tt.want) {
+					t.Errorf("expected %v query parameters, got %v", tt.want, r.URL.Query())
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			b := client.NewBulb(baseURL)
+
+			err = tt.call(b, context.Background())
+			if err != nil {
+				t.Errorf("Bulb %s error = %v", tt.name, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package mystrom
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Button holds all info and logic to talk your myStrom Button, Button Plus
+// or Button Plus 2nd generation device. Battery powered buttons only
+// respond while they are awake, e.g. after being charged or pressed.
+type Button struct {
+	device deviceAPI
+}
+
+// NewButton creates a new Button instance.
+func (c *Client) NewButton(baseURL *url.URL) *Button {
+	return &Button{
+		device: deviceAPI{
+			baseURL: baseURL,
+			client:  c,
+		},
+	}
+}
+
+// NewButton creates a new Button instance with a default client.
+func NewButton(baseURL *url.URL) *Button {
+	return NewClient().NewButton(baseURL)
+}
+
+// ButtonEvent is an interaction with a Button which can trigger an action.
+type ButtonEvent string
+
+const (
+	ButtonEventSingle     ButtonEvent = "single"
+	ButtonEventDouble     ButtonEvent = "double"
+	ButtonEventLong       ButtonEvent = "long"
+	ButtonEventTouch      ButtonEvent = "touch"
+	ButtonEventWheel      ButtonEvent = "wheel"       // Button Plus only
+	ButtonEventWheelFinal ButtonEvent = "wheel_final" // Button Plus only
+	ButtonEventGeneric    ButtonEvent = "generic"
+)
+
+// ButtonActions holds the action URLs of a Button. Actions are of the
+// form get://host/path or post://host/path?query.
+type ButtonActions struct {
+	Single     string `json:"single"`
+	Double     string `json:"double"`
+	Long       string `json:"long"`
+	Touch      string `json:"touch"`
+	Wheel      string `json:"wheel"`
+	WheelFinal string `json:"wheel_final"`
+	Generic    string `json:"generic"`
+}
+
+func (a ButtonActions) values() url.Values {
+	params := url.Values{}
+	for event, action := range map[ButtonEvent]string{
+		ButtonEventSingle:     a.Single,
+		ButtonEventDouble:     a.Double,
+		ButtonEventLong:       a.Long,
+		ButtonEventTouch:      a.Touch,
+		ButtonEventWheel:      a.Wheel,
+		ButtonEventWheelFinal: a.WheelFinal,
+		ButtonEventGeneric:    a.Generic,
+	} {
+		if action != "" {
+			params.Set(string(event), action)
+		}
+	}
+	return params
+}
+
+// ButtonState represents the state of a Button.
+type ButtonState struct {
+	ButtonActions
+
+	MAC             string  `json:"-"`
+	Type            string  `json:"type"`
+	Battery         bool    `json:"battery"`
+	Reachable       bool    `json:"reachable"`
+	MeshRoot        bool    `json:"meshroot"`
+	Charging        bool    `json:"charge"`
+	Voltage         float64 `json:"voltage"` // battery voltage in volts
+	FirmwareVersion string  `json:"fw_version"`
+}
+
+// State returns the current state of the Button including its battery level and actions.
+func (b *Button) State(ctx context.Context) (*ButtonState, error) {
+	state := ButtonState{}
+
+	mac, err := b.device.state(ctx, &state)
+	state.MAC = mac
+	return &state, err
+}
+
+// Actions returns the configured action URLs.
+func (b *Button) Actions(ctx context.Context) (*ButtonActions, error) {
+	state, err := b.State(ctx)
+	return &state.ButtonActions, err
+}
+
+// SetActions sets all non empty action URLs. Use SetAction to clear an action.
+func (b *Button) SetActions(ctx context.Context, actions ButtonActions) error {
+	return b.device.set(ctx, actions.values())
+}
+
+// SetAction sets the action URL for a single event, an empty action clears it.
+func (b *Button) SetAction(ctx context.Context, event ButtonEvent, action string) error {
+	return b.device.set(ctx, url.Values{string(event): []string{action}})
+}
+
+// ButtonSensors represents the sensor values of a Button Plus. Temperature is provided in °C.
+type ButtonSensors struct {
+	Temperature float64 `json:"temperature"`
+	Humidity    float64 `json:"humidity"` // relative humidity in percent
+}
+
+// Sensors returns the temperature and humidity measured by a Button Plus.
+func (b *Button) Sensors(ctx context.Context) (*ButtonSensors, error) {
+	sensors := ButtonSensors{}
+
+	req, err := b.device.client.newRequest(ctx, b.device.baseURL, http.MethodGet, "api/v1/sensors", nil, nil)
+	if err != nil {
+		return &sensors, err
+	}
+
+	_, err = b.device.client.doJSON(req, &sensors)
+	return &sensors, err
+}
+
+func (b *Button) URL() url.URL {
+	return *b.device.baseURL
+}
@@ -0,0 +1,189 @@
+package mystrom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+const buttonState = `{"5CCF7F000003": {"type": "button", "battery": true, "reachable": true, "meshroot": false, "charge": true, "voltage": 4.118, "fw_version": "2.74.10", "single": "get://192.168.1.10/toggle", "double": "", "long": "post://192.168.1.10/relay?state=0", "touch": "", "wheel": "", "wheel_final": "", "generic": ""}}`
+
+func TestButton_State(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		body       []byte
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *mystrom.ButtonState
+		wantErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				body:       []byte(buttonState),
+				statusCode: http.StatusOK,
+			},
+			want: &mystrom.ButtonState{
+				ButtonActions: mystrom.ButtonActions{
+					Single: "get://192.168.1.10/toggle",
+					Long:   "post://192.168.1.10/relay?state=0",
+				},
+				MAC:             "5CCF7F000003",
+				Type:            "button",
+				Battery:         true,
+				Reachable:       true,
+				Charging:        true,
+				Voltage:         4.118,
+				FirmwareVersion: "2.74.10",
+			},
+			wantErr: false,
+		},
+		{
+			name: "error",
+			args: args{
+				statusCode: http.StatusInternalServerError,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/device" {
+					t.Errorf("expected /api/v1/device path, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.args.statusCode)
+				_, _ = w.Write(tt.args.body)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			b := client.NewButton(baseURL)
+
+			state, err := b.State(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Button.State() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(state, tt.want) {
+				t.Errorf("Button.State() = %v, want %v", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestButton_SetActions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		call func(*mystrom.Button, context.Context) error
+		want url.Values
+	}{
+		{
+			name: "actions",
+			call: func(b *mystrom.Button, ctx context.Context) error {
+				return b.SetActions(ctx, mystrom.ButtonActions{
+					Single: "get://192.168.1.10/toggle",
+					Wheel:  "post://192.168.1.11/api/v1/device/5CCF7F000001?ramp=100",
+				})
+			},
+			want: url.Values{
+				"single": []string{"get://192.168.1.10/toggle"},
+				"wheel":  []string{"post://192.168.1.11/api/v1/device/5CCF7F000001?ramp=100"},
+			},
+		},
+		{
+			name: "clear action",
+			call: func(b *mystrom.Button, ctx context.Context) error {
+				return b.SetAction(ctx, mystrom.ButtonEventLong, "")
+			},
+			want: url.Values{"long": []string{""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && r.URL.Path == "/api/v1/device" {
+					_, _ = w.Write([]byte(buttonState))
+					return
+				}
+
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/device/5CCF7F000003" {
+					t.Errorf("expected /api/v1/device/5CCF7F000003 path, got %s", r.URL.Path)
+				}
+
+				if !reflect.DeepEqual(r.URL.Query(), tt.want) {
+					t.Errorf("expected %v query parameters, got %v", tt.want, r.URL.Query())
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			b := client.NewButton(baseURL)
+
+			err = tt.call(b, context.Background())
+			if err != nil {
+				t.Errorf("Button %s error = %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestButton_Sensors(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/sensors" {
+			t.Errorf("expected /api/v1/sensors path, got %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{"temperature": 21.25, "humidity": 48}`))
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sensors, err := mystrom.NewClient().NewButton(baseURL).Sensors(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (&mystrom.ButtonSensors{Temperature: 21.25, Humidity: 48}); !reflect.DeepEqual(sensors, want) {
+		t.Errorf("Button.Sensors() = %v, want %v", sensors, want)
+	}
+}
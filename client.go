@@ -11,7 +11,7 @@ import (
 	"net/url"
 )
 
-// ErrStatus respresents a non success status code error.
+// ErrStatus respresents a non success status code error, see APIError for details.
 var ErrStatus = errors.New("status code error")
 
 type Client struct {
@@ -19,6 +19,10 @@ type Client struct {
 	apiKey    string
 
 	httpClient *http.Client
+	retry      *RetryPolicy
+	limiter    *hostLimiter
+
+	middlewares []Middleware
 }
 
 func NewClient(opts ...Option) *Client {
@@ -61,7 +65,7 @@ func (c *Client) newRequest(
 	baseURL *url.URL,
 	method, path string,
 	params url.Values,
-	body interface{}, //nolint:unparam
+	body interface{},
 ) (*http.Request, error) {
 	if params == nil {
 		params = url.Values{}
@@ -116,7 +120,31 @@ func (c *Client) doJSON(req *http.Request, v interface{}) (*http.Response, error
 }
 
 func (c *Client) do(req *http.Request) (*http.Response, error) {
-	resp, err := c.httpClient.Do(req)
+	if c.retry != nil {
+		return c.retry.doRetry(req, c.send)
+	}
+
+	return c.send(req)
+}
+
+// doDiscard sends req and discards the response body.
+func (c *Client) doDiscard(req *http.Request) error {
+	resp, err := c.do(req)
+	if err == nil && resp.Body != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return err
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	if c.limiter != nil {
+		resp, err = c.limiter.roundTrip(req, c.roundTrip)
+	} else {
+		resp, err = c.roundTrip(req)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -129,11 +157,10 @@ func (c *Client) do(req *http.Request) (*http.Response, error) {
 			if err != nil {
 				return nil, fmt.Errorf("error reading body: %w", err)
 			}
-			body = bytes.TrimSpace(body)
 
-			return resp, fmt.Errorf("%s: %d, %w '%s'", http.StatusText(resp.StatusCode), resp.StatusCode, ErrStatus, body)
+			return resp, newAPIError(req, resp.StatusCode, bytes.TrimSpace(body))
 		}
-		return resp, fmt.Errorf("%s: %d, %w", http.StatusText(resp.StatusCode), resp.StatusCode, ErrStatus)
+		return resp, newAPIError(req, resp.StatusCode, nil)
 	}
 
 	return resp, err
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// discoveredDevice is the printable representation of a mystrom.Device.
+type discoveredDevice struct {
+	MAC        string             `json:"mac"`
+	IP         string             `json:"ip"`
+	Type       mystrom.DeviceType `json:"type"`
+	TypeName   string             `json:"type_name"`
+	Cloud      bool               `json:"cloud"`
+	Registered bool               `json:"registered"`
+	MeshChild  bool               `json:"mesh_child"`
+}
+
+func newDiscoveredDevice(d mystrom.Device) discoveredDevice {
+	ip := ""
+	if u, err := d.URL(); err == nil {
+		ip = u.Hostname()
+	}
+
+	return discoveredDevice{
+		MAC:        d.MAC.String(),
+		IP:         ip,
+		Type:       d.Type,
+		TypeName:   d.Type.String(),
+		Cloud:      d.Cloud,
+		Registered: d.Registered,
+		MeshChild:  d.MeshChild,
+	}
+}
+
+func (d discoveredDevice) flags() string {
+	var flags []string
+	if d.Cloud {
+		flags = append(flags, "cloud")
+	}
+	if d.Registered {
+		flags = append(flags, "registered")
+	}
+	if d.MeshChild {
+		flags = append(flags, "mesh-child")
+	}
+	return strings.Join(flags, ",")
+}
+
+// typeFilter matches device types by number or by a case insensitive part
+// of their name, e.g. "switch" matches all switches.
+type typeFilter []string
+
+func (f typeFilter) match(t mystrom.DeviceType) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	name := strings.ToLower(t.String())
+	for _, filter := range f {
+		if filter == strconv.Itoa(int(t)) || strings.Contains(name, strings.ToLower(filter)) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTypeFilter(s string) typeFilter {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// deviceWriter prints discovered devices in a given format.
+type deviceWriter interface {
+	Write(discoveredDevice) error
+	Flush() error
+}
+
+// newDeviceWriter returns a writer for format, stream writes every device
+// as soon as it is written instead of aligning all devices on Flush.
+func newDeviceWriter(w io.Writer, format string, stream bool) (deviceWriter, error) {
+	switch format {
+	case "table":
+		if stream {
+			st := newStreamTableWriter(w)
+			return st, st.write("MAC", "IP", "TYPE", "FLAGS")
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, err := fmt.Fprintln(tw, "MAC\tIP\tTYPE\tFLAGS")
+		return &tableWriter{w: tw}, err
+	case "json":
+		return &jsonWriter{w: w}, nil
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write([]string{"mac", "ip", "type", "type_name", "cloud", "registered", "mesh_child"})
+	default:
+		return nil, fmt.Errorf("format '%s' is not defined", format)
+	}
+}
+
+type tableWriter struct{ w *tabwriter.Writer }
+
+func (t *tableWriter) Write(d discoveredDevice) error {
+	_, err := fmt.Fprintf(t.w, "%s\t%s\t%s\t%s\n", d.MAC, d.IP, d.TypeName, d.flags())
+	return err
+}
+
+func (t *tableWriter) Flush() error { return t.w.Flush() }
+
+// streamTableWriter aligns devices by fixed column widths, as the devices
+// to come are not known when a device is written.
+type streamTableWriter struct {
+	w         io.Writer
+	typeWidth int
+}
+
+func newStreamTableWriter(w io.Writer) *streamTableWriter {
+	t := &streamTableWriter{w: w, typeWidth: len("TYPE")}
+	for _, typ := range deviceTypes {
+		if n := len(typ.String()); n > t.typeWidth {
+			t.typeWidth = n
+		}
+	}
+	return t
+}
+
+func (t *streamTableWriter) write(mac, ip, typ, flags string) error {
+	_, err := fmt.Fprintf(t.w, "%-17s  %-15s  %-*s  %s\n", mac, ip, t.typeWidth, typ, flags)
+	return err
+}
+
+func (t *streamTableWriter) Write(d discoveredDevice) error {
+	return t.write(d.MAC, d.IP, d.TypeName, d.flags())
+}
+
+func (t *streamTableWriter) Flush() error { return nil }
+
+type jsonWriter struct {
+	w       io.Writer
+	devices []discoveredDevice
+}
+
+func (j *jsonWriter) Write(d discoveredDevice) error {
+	j.devices = append(j.devices, d)
+	return nil
+}
+
+func (j *jsonWriter) Flush() error {
+	if j.devices == nil {
+		j.devices = []discoveredDevice{}
+	}
+
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.devices)
+}
+
+type ndjsonWriter struct{ enc *json.Encoder }
+
+func (n *ndjsonWriter) Write(d discoveredDevice) error { return n.enc.Encode(d) }
+
+func (n *ndjsonWriter) Flush() error { return nil }
+
+type csvWriter struct{ w *csv.Writer }
+
+func (c *csvWriter) Write(d discoveredDevice) error {
+	return c.w.Write([]string{
+		d.MAC,
+		d.IP,
+		strconv.Itoa(int(d.Type)),
+		d.TypeName,
+		strconv.FormatBool(d.Cloud),
+		strconv.FormatBool(d.Registered),
+		strconv.FormatBool(d.MeshChild),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func discover(args []string) error {
+	flags := flag.NewFlagSet("discover", flag.ContinueOnError)
+	address := flags.String("address", ":7979", "address to listen for discovery beacons")
+	format := flags.String("format", "table", "output format: table, json, ndjson or csv")
+	once := flags.Bool("once", false, "collect beacons, print a deduplicated list and exit")
+	intervals := flags.Int("intervals", 2, "number of beacon intervals to collect with -once")
+	timeout := flags.Duration("timeout", 0, "stop discovering after this duration")
+	types := flags.String("type", "", "comma separated device types to include, by number or name, e.g. switch")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" && !*once {
+		return fmt.Errorf("format json requires -once, use ndjson to stream devices")
+	}
+
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	switch {
+	case *timeout > 0:
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	case *once:
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*intervals)*mystrom.BeaconInterval)
+		defer cancel()
+	}
+
+	w, err := newDeviceWriter(os.Stdout, *format, !*once)
+	if err != nil {
+		return err
+	}
+
+	d := mystrom.Discover{Address: *address}
+	events, err := d.Devices(ctx)
+	if err != nil {
+		return fmt.Errorf("error discovering devices: %w", err)
+	}
+
+	filter := parseTypeFilter(*types)
+	if *once {
+		return discoverOnce(events, filter, w)
+	}
+	return discoverStream(events, filter, w)
+}
+
+func discoverStream(events <-chan mystrom.DeviceEvent, filter typeFilter, w deviceWriter) error {
+	for event := range events {
+		switch event.Type {
+		case mystrom.DeviceError:
+			log.Printf("error discovering devices: %s", event.Err)
+			continue
+		case mystrom.DeviceDisappeared:
+			continue
+		}
+
+		if !filter.match(event.Device.Type) {
+			continue
+		}
+
+		err := w.Write(newDiscoveredDevice(event.Device))
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func discoverOnce(events <-chan mystrom.DeviceEvent, filter typeFilter, w deviceWriter) error {
+	for _, device := range collect(events, filter) {
+		err := w.Write(newDiscoveredDevice(device))
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// collect returns the devices matching filter in events sorted by MAC
+// address once the events channel was closed.
+func collect(events <-chan mystrom.DeviceEvent, filter typeFilter) []mystrom.Device {
+	devices := map[string]mystrom.Device{}
+	for event := range events {
+		switch event.Type {
+		case mystrom.DeviceError:
+			log.Printf("error discovering devices: %s", event.Err)
+		case mystrom.DeviceAppeared, mystrom.DeviceUpdated:
+			if filter.match(event.Device.Type) {
+				devices[event.Device.MAC.String()] = event.Device
+			}
+		}
+	}
+
+	macs := make([]string, 0, len(devices))
+	for mac := range devices {
+		macs = append(macs, mac)
+	}
+	sort.Strings(macs)
+
+	list := make([]mystrom.Device, 0, len(macs))
+	for _, mac := range macs {
+		list = append(list, devices[mac])
+	}
+	return list
+}
+
+// discoverDevices collects the devices matching filter which sent a beacon
+// within the given number of beacon intervals.
+func discoverDevices(address string, intervals int, filter typeFilter) ([]mystrom.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(intervals)*mystrom.BeaconInterval)
+	defer cancel()
+
+	d := mystrom.Discover{Address: address}
+	events, err := d.Devices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering devices: %w", err)
+	}
+
+	return collect(events, filter), nil
+}
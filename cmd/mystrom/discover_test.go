@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func testEvents() <-chan mystrom.DeviceEvent {
+	events := make(chan mystrom.DeviceEvent, 5)
+	events <- mystrom.DeviceEvent{Type: mystrom.DeviceAppeared, Device: mystrom.Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 30), Port: 7979},
+		MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, 2},
+		Type:    mystrom.DeviceTypeBulb,
+	}}
+	events <- mystrom.DeviceEvent{Type: mystrom.DeviceAppeared, Device: mystrom.Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 20), Port: 7979},
+		MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, 1},
+		Type:    mystrom.DeviceTypeSwitchEU,
+	}}
+	events <- mystrom.DeviceEvent{Type: mystrom.DeviceError, Err: errors.New("payload too small: 2")}
+	events <- mystrom.DeviceEvent{Type: mystrom.DeviceUpdated, Device: mystrom.Device{
+		Address:    &net.UDPAddr{IP: net.IPv4(192, 168, 1, 21), Port: 7979},
+		MAC:        net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, 1},
+		Type:       mystrom.DeviceTypeSwitchEU,
+		Cloud:      true,
+		Registered: true,
+	}}
+	close(events)
+	return events
+}
+
+func TestDiscoverOnce(t *testing.T) {
+	tests := []struct {
+		format string
+		filter typeFilter
+		want   string
+	}{
+		{
+			format: "table",
+			want: "MAC                IP            TYPE       FLAGS\n" +
+				"5c:cf:7f:00:00:01  192.168.1.21  Switch EU  cloud,registered\n" +
+				"5c:cf:7f:00:00:02  192.168.1.30  Bulb       \n",
+		},
+		{
+			format: "csv",
+			filter: parseTypeFilter("switch"),
+			want: "mac,ip,type,type_name,cloud,registered,mesh_child\n" +
+				"5c:cf:7f:00:00:01,192.168.1.21,107,Switch EU,true,true,false\n",
+		},
+		{
+			format: "ndjson",
+			filter: parseTypeFilter("102"),
+			want:   `{"mac":"5c:cf:7f:00:00:02","ip":"192.168.1.30","type":102,"type_name":"Bulb","cloud":false,"registered":false,"mesh_child":false}` + "\n",
+		},
+		{
+			format: "json",
+			filter: parseTypeFilter("gateway"),
+			want:   "[]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newDeviceWriter(&buf, tt.format, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = discoverOnce(testEvents(), tt.filter, w)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("discoverOnce() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverStream(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newDeviceWriter(&buf, "table", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = discoverStream(testEvents(), nil, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and 3 devices, got:\n%s", buf.String())
+	}
+
+	// every device is aligned to the header without knowing the others
+	header := lines[0]
+	for _, line := range lines[1:] {
+		for _, column := range []struct{ name, value string }{
+			{"IP", "192.168.1."},
+			{"TYPE", strings.Fields(line)[2]},
+		} {
+			if strings.Index(line, column.value) != strings.Index(header, column.name) {
+				t.Errorf("column %s not aligned:\n%s\n%s", column.name, header, line)
+			}
+		}
+	}
+}
+
+func TestNewDeviceWriter_invalid(t *testing.T) {
+	_, err := newDeviceWriter(&bytes.Buffer{}, "xml", false)
+	if err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+type target struct {
+	name    string
+	baseURL *url.URL
+	mac     string
+	typ     mystrom.DeviceType
+}
+
+// parseTarget parses targets of the form [name=]address[,type=type]. The
+// type is read from the device unless given, which is required for devices
+// without the info endpoint like Switches with old firmware.
+func parseTarget(s string) (target, error) {
+	s, option, hasOption := strings.Cut(s, ",")
+
+	name, address, ok := strings.Cut(s, "=")
+	if !ok {
+		address = s
+		name = s
+	}
+
+	u, err := url.Parse("http://" + address)
+	if err != nil {
+		return target{}, fmt.Errorf("error parsing url for target %s: %w", s, err)
+	}
+
+	t := target{name: name, baseURL: u}
+	if !hasOption {
+		return t, nil
+	}
+
+	key, value, _ := strings.Cut(option, "=")
+	if key != "type" {
+		return target{}, fmt.Errorf("option '%s' of target %s is not defined", key, name)
+	}
+
+	types, err := parseDeviceTypes(value)
+	if err != nil {
+		return target{}, err
+	}
+	if len(types) != 1 {
+		return target{}, fmt.Errorf("type '%s' of target %s matches %d device types, use its number or full name", value, name, len(types))
+	}
+	t.typ = types[0]
+
+	return t, nil
+}
+
+type targetsFlag []target
+
+func (t *targetsFlag) String() string {
+	names := make([]string, 0, len(*t))
+	for _, target := range *t {
+		names = append(names, target.name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (t *targetsFlag) Set(s string) error {
+	target, err := parseTarget(s)
+	if err != nil {
+		return err
+	}
+	*t = append(*t, target)
+	return nil
+}
+
+type metric struct {
+	name   string
+	help   string
+	typ    string
+	labels string
+	value  float64
+}
+
+// collector gathers metrics of myStrom devices in the Prometheus text exposition format.
+type collector struct {
+	client  *mystrom.Client
+	timeout time.Duration
+
+	mu         sync.Mutex
+	static     []target
+	discovered map[string]target
+	identified map[string]target // static targets with MAC and type from their info
+	errors     map[string]float64
+}
+
+func newCollector(client *mystrom.Client, static []target) *collector {
+	return &collector{
+		client:     client,
+		timeout:    5 * time.Second,
+		static:     static,
+		discovered: map[string]target{},
+		identified: map[string]target{},
+		errors:     map[string]float64{},
+	}
+}
+
+func (c *collector) add(device mystrom.Device) error {
+	u, err := device.URL()
+	if err != nil {
+		return err
+	}
+
+	mac := device.MAC.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.discovered[mac] = target{name: mac, baseURL: u, mac: mac, typ: device.Type}
+	return nil
+}
+
+func (c *collector) targets() []target {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	targets := make([]target, 0, len(c.static)+len(c.discovered))
+	targets = append(targets, c.static...)
+	for _, t := range c.discovered {
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+func labels(t target) string {
+	typ := ""
+	if t.typ != 0 {
+		typ = t.typ.String()
+	}
+	return fmt.Sprintf(`mac="%s",type="%s",name="%s"`, escape(t.mac), escape(typ), escape(t.name))
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func (c *collector) scrape(ctx context.Context, t target) []metric {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var metrics []metric
+	var errs int
+
+	t, checked, err := c.identify(ctx, t)
+	if err != nil {
+		errs++
+	}
+
+	l := labels(t)
+	gauge := func(name, help string, value float64) {
+		metrics = append(metrics, metric{name: name, help: help, typ: "gauge", labels: l, value: value})
+	}
+
+	switch t.typ {
+	case mystrom.DeviceTypeMotionSensor:
+		m := c.client.NewMotionSensor(t.baseURL)
+		if temp, err := m.Temperature(ctx); err == nil {
+			gauge("mystrom_temperature_measured_celsius", "Measured temperature in °C.", temp.Measured)
+			gauge("mystrom_temperature_compensated_celsius", "Compensated temperature in °C.", temp.Compensated)
+		} else {
+			errs++
+		}
+		if light, err := m.Light(ctx); err == nil {
+			gauge("mystrom_light_lux", "Light intensity in lux.", light.Intensity)
+		} else {
+			errs++
+		}
+		if motion, err := m.Motion(ctx); err == nil {
+			gauge("mystrom_motion", "1 if motion is detected.", boolValue(motion))
+		} else {
+			errs++
+		}
+	case mystrom.DeviceTypeSwitchCH, mystrom.DeviceTypeSwitchEU, mystrom.DeviceTypeSwitchZero:
+		sw := c.client.NewSwitch(t.baseURL)
+		if report, err := sw.Report(ctx); err == nil {
+			gauge("mystrom_power_watts", "Current power consumption in watts.", report.Power)
+			gauge("mystrom_relay_state", "1 if the relay is on.", boolValue(report.Relay))
+			if report.BootID != "" || report.TimeSinceBoot > 0 {
+				gauge("mystrom_energy_since_boot_joules", "Energy consumed since boot in joules (watt seconds).", report.EnergySinceBoot)
+				gauge("mystrom_time_since_boot_seconds", "Time since boot in seconds.", float64(report.TimeSinceBoot))
+			}
+		} else {
+			errs++
+		}
+		if temp, err := sw.Temperature(ctx); err == nil {
+			gauge("mystrom_temperature_measured_celsius", "Measured temperature in °C.", temp.Measured)
+			gauge("mystrom_temperature_compensated_celsius", "Compensated temperature in °C.", temp.Compensated)
+		} else {
+			errs++
+		}
+	default:
+		// other devices only report whether they respond
+		if t.typ != 0 && !checked {
+			if _, err := c.client.Info(ctx, t.baseURL); err != nil {
+				errs++
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.errors[t.name] += float64(errs)
+	total := c.errors[t.name]
+	c.mu.Unlock()
+
+	gauge("mystrom_up", "1 if the device could be scraped without errors.", boolValue(errs == 0))
+	metrics = append(metrics, metric{
+		name:   "mystrom_scrape_errors_total",
+		help:   "Total number of failed requests while scraping the device.",
+		typ:    "counter",
+		labels: l,
+		value:  total,
+	})
+
+	return metrics
+}
+
+// identify returns t with the MAC address and type from the info of the
+// device, which is requested once per static target and then cached to
+// keep the labels stable. checked is true if the info was requested. A
+// target with a given type is scraped by it if its info is not available.
+func (c *collector) identify(ctx context.Context, t target) (_ target, checked bool, _ error) {
+	if t.mac != "" && t.typ != 0 {
+		return t, false, nil
+	}
+
+	c.mu.Lock()
+	known, ok := c.identified[t.name]
+	c.mu.Unlock()
+	if ok {
+		return known, false, nil
+	}
+
+	info, err := c.client.Info(ctx, t.baseURL)
+	if err != nil && t.typ != 0 {
+		// scrape by the given type, a device without the info endpoint
+		// is not asked again
+		if errors.Is(err, mystrom.ErrStatus) {
+			c.mu.Lock()
+			c.identified[t.name] = t
+			c.mu.Unlock()
+		}
+		return t, false, nil
+	}
+	if err != nil {
+		return t, true, err
+	}
+
+	t.mac = info.MAC.String()
+	t.typ = info.Type
+
+	c.mu.Lock()
+	c.identified[t.name] = t
+	c.mu.Unlock()
+
+	return t, true, nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (c *collector) collect(ctx context.Context) []metric {
+	targets := c.targets()
+	results := make([][]metric, len(targets))
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t target) {
+			defer wg.Done()
+			results[i] = c.scrape(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var metrics []metric
+	for _, r := range results {
+		metrics = append(metrics, r...)
+	}
+
+	sort.SliceStable(metrics, func(i, j int) bool {
+		if metrics[i].name != metrics[j].name {
+			return metrics[i].name < metrics[j].name
+		}
+		return metrics[i].labels < metrics[j].labels
+	})
+	return metrics
+}
+
+func writeMetrics(w io.Writer, metrics []metric) error {
+	var last string
+	for _, m := range metrics {
+		if m.name != last {
+			_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.typ)
+			if err != nil {
+				return err
+			}
+			last = m.name
+		}
+
+		_, err := fmt.Fprintf(w, "%s{%s} %g\n", m.name, m.labels, m.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	err := writeMetrics(w, c.collect(r.Context()))
+	if err != nil {
+		log.Printf("error writing metrics: %s", err)
+	}
+}
+
+func (c *collector) remove(device mystrom.Device) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.discovered, device.MAC.String())
+}
+
+func (c *collector) discover(ctx context.Context, address string) error {
+	discover := mystrom.Discover{Address: address}
+	events, err := discover.Devices(ctx)
+	if err != nil {
+		return fmt.Errorf("error discovering devices: %w", err)
+	}
+
+	go func() {
+		for event := range events {
+			switch event.Type {
+			case mystrom.DeviceAppeared, mystrom.DeviceUpdated:
+				err := c.add(event.Device)
+				if err != nil {
+					log.Printf("error adding device %s: %s", event.Device.MAC, err)
+				}
+			case mystrom.DeviceDisappeared:
+				c.remove(event.Device)
+			case mystrom.DeviceError:
+				log.Printf("error discovering devices: %s", event.Err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func exporter(args []string) error {
+	var targets targetsFlag
+
+	flags := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	listen := flags.String("listen", ":9452", "address to serve metrics on")
+	discover := flags.Bool("discover", false, "scrape devices found by discovery")
+	discoverAddress := flags.String("discover-address", ":7979", "address to listen for discovery beacons")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout for scraping a single device")
+	flags.Var(&targets, "target", "static target of the form [name=]address[,type=type], can be repeated")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 && !*discover {
+		return fmt.Errorf("exporter requires at least one -target or -discover")
+	}
+
+	c := newCollector(mystrom.NewClient(), targets)
+	c.timeout = *timeout
+
+	if *discover {
+		err = c.discover(context.Background(), *discoverAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c)
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("serving metrics on %s/metrics", *listen)
+	return server.ListenAndServe()
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func fakeSwitch(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/info":
+			_, _ = w.Write([]byte(`{"version": "3.82.60", "mac": "5CCF7F123456", "type": 107}`))
+		case "/report":
+			_, _ = w.Write([]byte(`{"power": 42.5, "relay": true, "boot_id": "a", "energy_since_boot": 1200, "time_since_boot": 60}`))
+		case "/api/v1/temperature":
+			_, _ = w.Write([]byte(`{"measured": 25, "compensation": 3, "compensated": 22}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCollector(t *testing.T) {
+	ts := fakeSwitch(t)
+	defer ts.Close()
+
+	kitchen, err := parseTarget("kitchen=" + strings.TrimPrefix(ts.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newCollector(mystrom.NewClient(), []target{kitchen})
+
+	err = c.add(mystrom.Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 21), Port: 7979},
+		MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, 1},
+		Type:    mystrom.DeviceTypeSwitchCH,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host := c.discovered["5c:cf:7f:00:00:01"].baseURL.Host; host != "192.168.1.21" {
+		t.Fatalf("expected discovered device at 192.168.1.21, got %s", host)
+	}
+
+	// point the discovered device to a server which is gone
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+	c.discovered["5c:cf:7f:00:00:01"].baseURL.Host = strings.TrimPrefix(offline.URL, "http://")
+
+	var buf bytes.Buffer
+	err = writeMetrics(&buf, c.collect(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kitchenLabels := `{mac="5c:cf:7f:12:34:56",type="Switch EU",name="kitchen"}`
+	offlineLabels := `{mac="5c:cf:7f:00:00:01",type="Switch CH",name="5c:cf:7f:00:00:01"}`
+	for _, want := range []string{
+		"# TYPE mystrom_power_watts gauge",
+		"mystrom_power_watts" + kitchenLabels + " 42.5",
+		"mystrom_relay_state" + kitchenLabels + " 1",
+		"mystrom_energy_since_boot_joules" + kitchenLabels + " 1200",
+		"mystrom_temperature_measured_celsius" + kitchenLabels + " 25",
+		"mystrom_temperature_compensated_celsius" + kitchenLabels + " 22",
+		"mystrom_up" + kitchenLabels + " 1",
+		"# TYPE mystrom_scrape_errors_total counter",
+		"mystrom_scrape_errors_total" + kitchenLabels + " 0",
+		"mystrom_up" + offlineLabels + " 0",
+		"mystrom_scrape_errors_total" + offlineLabels + " 2",
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	if strings.Count(buf.String(), "# TYPE mystrom_up gauge") != 1 {
+		t.Errorf("expected a single TYPE line per metric, got:\n%s", buf.String())
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantName string
+		wantHost string
+		wantType mystrom.DeviceType
+	}{
+		{"192.168.1.20", "192.168.1.20", "192.168.1.20", 0},
+		{"kitchen=192.168.1.20", "kitchen", "192.168.1.20", 0},
+		{"office=switch.local:8080", "office", "switch.local:8080", 0},
+		{"garage=192.168.1.21,type=switch ch", "garage", "192.168.1.21", mystrom.DeviceTypeSwitchCH},
+		{"192.168.1.22,type=107", "192.168.1.22", "192.168.1.22", mystrom.DeviceTypeSwitchEU},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseTarget(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.name != tt.wantName || got.baseURL.Host != tt.wantHost || got.typ != tt.wantType {
+				t.Errorf("parseTarget() = %s %s %s, want %s %s %s", got.name, got.baseURL.Host, got.typ, tt.wantName, tt.wantHost, tt.wantType)
+			}
+		})
+	}
+
+	for _, in := range []string{"a=192.168.1.20,type=switch", "a=192.168.1.20,type=toaster", "a=192.168.1.20,mac=5CCF7F123456"} {
+		if _, err := parseTarget(in); err == nil {
+			t.Errorf("parseTarget(%s) expected error", in)
+		}
+	}
+}
+
+func TestCollector_identify(t *testing.T) {
+	ts := fakeSwitch(t)
+
+	kitchen, err := parseTarget("kitchen=" + strings.TrimPrefix(ts.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bulb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/info" {
+			t.Errorf("unexpected request to bulb %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"version": "2.58.0", "mac": "5CCF7F000002", "type": 102}`))
+	}))
+	defer bulb.Close()
+
+	c := newCollector(mystrom.NewClient(), []target{kitchen})
+	err = c.add(mystrom.Device{
+		Address: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7979},
+		MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, 2},
+		Type:    mystrom.DeviceTypeBulb,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.discovered["5c:cf:7f:00:00:02"].baseURL.Host = strings.TrimPrefix(bulb.URL, "http://")
+
+	var buf bytes.Buffer
+	if err := writeMetrics(&buf, c.collect(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+
+	bulbLabels := `{mac="5c:cf:7f:00:00:02",type="Bulb",name="5c:cf:7f:00:00:02"}`
+	for _, want := range []string{
+		"mystrom_up" + bulbLabels + " 1",
+		"mystrom_scrape_errors_total" + bulbLabels + " 0",
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "mystrom_power_watts"+bulbLabels) {
+		t.Errorf("expected no switch metrics for a bulb, got:\n%s", buf.String())
+	}
+
+	// the static target keeps its labels once its info was read
+	ts.Close()
+	buf.Reset()
+	if err := writeMetrics(&buf, c.collect(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+
+	kitchenLabels := `{mac="5c:cf:7f:12:34:56",type="Switch EU",name="kitchen"}`
+	if !strings.Contains(buf.String(), "mystrom_up"+kitchenLabels+" 0\n") {
+		t.Errorf("expected cached labels for offline static target, got:\n%s", buf.String())
+	}
+}
+
+func TestCollector_typedTarget(t *testing.T) {
+	var mu sync.Mutex
+	infos := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report":
+			_, _ = w.Write([]byte(`{"power": 12.5, "relay": true}`))
+		case "/api/v1/temperature":
+			_, _ = w.Write([]byte(`{"measured": 25, "compensation": 3, "compensated": 22}`))
+		default:
+			// old firmware without the info endpoint
+			mu.Lock()
+			infos++
+			mu.Unlock()
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	garage, err := parseTarget("garage=" + strings.TrimPrefix(ts.URL, "http://") + ",type=switch ch")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newCollector(mystrom.NewClient(), []target{garage})
+
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		if err := writeMetrics(&buf, c.collect(context.Background())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	labels := `{mac="",type="Switch CH",name="garage"}`
+	for _, want := range []string{
+		"mystrom_up" + labels + " 1",
+		"mystrom_power_watts" + labels + " 12.5",
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+	if infos != 1 {
+		t.Errorf("expected info to be requested once, got %d", infos)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// firmwareResult is the outcome of updating a single device.
+type firmwareResult struct {
+	device  mystrom.Device
+	before  mystrom.FirmwareVersion
+	after   mystrom.FirmwareVersion
+	skipped bool
+	err     error
+}
+
+func (r firmwareResult) String() string {
+	ip := ""
+	if u, err := r.device.URL(); err == nil {
+		ip = u.Hostname()
+	}
+
+	switch {
+	case r.err != nil:
+		return fmt.Sprintf("%s  %s  error: %s", r.device.MAC, ip, r.err)
+	case r.skipped:
+		return fmt.Sprintf("%s  %s  %s up to date", r.device.MAC, ip, r.before)
+	default:
+		return fmt.Sprintf("%s  %s  %s -> %s", r.device.MAC, ip, r.before, r.after)
+	}
+}
+
+// rollout updates devices in batches, all devices of a batch are updated
+// concurrently and batches are separated by a pause.
+type rollout struct {
+	batch           int
+	pause           time.Duration
+	continueOnError bool
+	update          func(mystrom.Device) firmwareResult
+}
+
+// run updates all devices and returns the failed results. It stops after
+// the first batch with a failure unless continueOnError is set.
+func (r rollout) run(devices []mystrom.Device, out io.Writer) []firmwareResult {
+	batch := r.batch
+	if batch < 1 {
+		batch = 1
+	}
+
+	var failed []firmwareResult
+	for start := 0; start < len(devices); start += batch {
+		if start > 0 && r.pause > 0 {
+			time.Sleep(r.pause)
+		}
+
+		end := start + batch
+		if end > len(devices) {
+			end = len(devices)
+		}
+
+		results := make([]firmwareResult, end-start)
+		wg := sync.WaitGroup{}
+		for i, device := range devices[start:end] {
+			wg.Add(1)
+			go func(i int, device mystrom.Device) {
+				defer wg.Done()
+				results[i] = r.update(device)
+			}(i, device)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			fmt.Fprintln(out, result)
+			if result.err != nil {
+				failed = append(failed, result)
+			}
+		}
+
+		if len(failed) > 0 && !r.continueOnError {
+			if end < len(devices) {
+				fmt.Fprintf(out, "stopped rollout, %d devices not updated\n", len(devices)-end)
+			}
+			break
+		}
+	}
+
+	return failed
+}
+
+// waitUpdated waits until the device is back online with a version other
+// than before. Only beacons received after since are used to resolve the
+// device, as it may come back on another address.
+func waitUpdated(
+	ctx context.Context,
+	client *mystrom.Client,
+	registry *mystrom.Registry,
+	mac net.HardwareAddr,
+	before mystrom.FirmwareVersion,
+	since time.Time,
+) (mystrom.FirmwareVersion, error) {
+	resolver := mystrom.ResolverFunc(func(ctx context.Context, mac net.HardwareAddr) (*url.URL, error) {
+		return registry.ResolveSince(ctx, mac, since)
+	})
+
+	for {
+		_, info, err := client.WaitOnline(ctx, resolver, mac)
+		if err != nil {
+			return "", err
+		}
+
+		if v := mystrom.FirmwareVersion(info.Version); v != before {
+			return v, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("device %s still runs %s", mac, before)
+		case <-time.After(mystrom.BeaconInterval):
+		}
+	}
+}
+
+func firmwareStatus(client *mystrom.Client, devices []mystrom.Device, timeout time.Duration) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MAC\tIP\tTYPE\tVERSION")
+
+	code := 0
+	for _, device := range devices {
+		d := newDiscoveredDevice(device)
+
+		version, err := deviceFirmware(client, device, timeout)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t%s\t%s\terror: %s\n", d.MAC, d.IP, d.TypeName, err)
+			if c := classify(err); c > code {
+				code = c
+			}
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.MAC, d.IP, d.TypeName, version)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return &exitError{code: code, err: errors.New("firmware status failed")}
+	}
+	return nil
+}
+
+func deviceFirmware(client *mystrom.Client, device mystrom.Device, timeout time.Duration) (mystrom.FirmwareVersion, error) {
+	u, err := device.URL()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return client.Firmware(ctx, u)
+}
+
+// updateType returns the single device type a firmware update is limited to.
+func updateType(types string) (mystrom.DeviceType, error) {
+	if types == "" {
+		return 0, errors.New("firmware update requires -type to limit the update to the devices the image is built for")
+	}
+
+	parsed, err := parseDeviceTypes(types)
+	if err != nil {
+		return 0, err
+	}
+	if len(parsed) != 1 {
+		return 0, fmt.Errorf("firmware update requires a single device type, '%s' matches %d types", types, len(parsed))
+	}
+	return parsed[0], nil
+}
+
+func firmware(args []string) error {
+	if len(args) == 0 || (args[0] != "status" && args[0] != "update") {
+		return errors.New("firmware requires a command: status or update")
+	}
+	command := args[0]
+
+	flags := flag.NewFlagSet("firmware "+command, flag.ContinueOnError)
+	address := flags.String("address", ":7979", "address to listen for discovery beacons")
+	intervals := flags.Int("intervals", 2, "number of beacon intervals to discover devices")
+	types := flags.String("type", "", "comma separated device types to include, by number or name, e.g. switch, update requires a single type")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout for requests to a single device")
+	file := flags.String("file", "", "firmware image to upload")
+	uploadTimeout := flags.Duration("upload-timeout", 5*time.Minute, "timeout for uploading the firmware to a single device")
+	version := flags.String("version", "", "skip devices already running this version or newer")
+	batch := flags.Int("batch", 1, "number of devices updated at the same time")
+	pause := flags.Duration("pause", time.Minute, "pause between batches")
+	continueOnError := flags.Bool("continue-on-error", false, "keep updating after a batch with a failed device")
+	wait := flags.Duration("wait", 5*time.Minute, "time to wait for a device to come back after the upload, 0 does not wait")
+	dryRun := flags.Bool("dry-run", false, "only print the devices which would be updated")
+
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	filter := parseTypeFilter(*types)
+	if command == "update" {
+		if *file == "" {
+			return errors.New("firmware update requires -file")
+		}
+
+		// an image only fits a single device type
+		typ, err := updateType(*types)
+		if err != nil {
+			return err
+		}
+		filter = typeFilter{strconv.Itoa(int(typ))}
+	}
+
+	devices, err := discoverDevices(*address, *intervals, filter)
+	if err != nil {
+		return err
+	}
+
+	client := mystrom.NewClient()
+	if command == "status" {
+		return firmwareStatus(client, devices, *timeout)
+	}
+
+	registry := &mystrom.Registry{}
+	if *wait > 0 && !*dryRun {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		d := &mystrom.Discover{Address: *address}
+		go func() {
+			err := registry.Run(ctx, d)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("error discovering devices: %s", err)
+			}
+		}()
+	}
+
+	target := mystrom.FirmwareVersion(*version)
+	r := rollout{
+		batch:           *batch,
+		pause:           *pause,
+		continueOnError: *continueOnError,
+		update: func(device mystrom.Device) firmwareResult {
+			result := firmwareResult{device: device}
+
+			result.before, result.err = deviceFirmware(client, device, *timeout)
+			if result.err != nil {
+				return result
+			}
+
+			if target != "" && result.before.Compare(target) >= 0 {
+				result.skipped = true
+				return result
+			}
+
+			if *dryRun {
+				result.after = "(dry run)"
+				return result
+			}
+
+			u, err := device.URL()
+			if err != nil {
+				result.err = err
+				return result
+			}
+
+			uploadCtx, cancelUpload := context.WithTimeout(context.Background(), *uploadTimeout)
+			defer cancelUpload()
+
+			progress := 0
+			result.err = client.UpdateFirmwareFile(uploadCtx, u, *file, func(sent, total int64) {
+				if p := int(sent * 100 / total); p/25 > progress/25 {
+					progress = p
+					log.Printf("%s uploaded %d%%", device.MAC, p)
+				}
+			})
+			if result.err != nil || *wait <= 0 {
+				return result
+			}
+			uploaded := time.Now()
+
+			ctx, cancel := context.WithTimeout(context.Background(), *wait)
+			defer cancel()
+
+			result.after, result.err = waitUpdated(ctx, client, registry, device.MAC, result.before, uploaded)
+			return result
+		},
+	}
+
+	failed := r.run(devices, os.Stdout)
+	if len(failed) > 0 {
+		code := 0
+		for _, result := range failed {
+			if c := classify(result.err); c > code {
+				code = c
+			}
+		}
+		return &exitError{code: code, err: fmt.Errorf("firmware update failed for %d devices", len(failed))}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func TestRollout(t *testing.T) {
+	devices := make([]mystrom.Device, 5)
+	for i := range devices {
+		devices[i] = mystrom.Device{
+			Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, byte(20+i)), Port: 7979},
+			MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, byte(i + 1)},
+			Type:    mystrom.DeviceTypeSwitchEU,
+		}
+	}
+
+	tests := []struct {
+		name            string
+		continueOnError bool
+		wantUpdated     int
+		wantStopped     bool
+	}{
+		{name: "stop on error", wantUpdated: 4, wantStopped: true},
+		{name: "continue on error", continueOnError: true, wantUpdated: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu := sync.Mutex{}
+			updated := 0
+
+			r := rollout{
+				batch:           2,
+				continueOnError: tt.continueOnError,
+				update: func(d mystrom.Device) firmwareResult {
+					mu.Lock()
+					updated++
+					mu.Unlock()
+
+					result := firmwareResult{device: d, before: "3.82.59", after: "3.82.60"}
+					switch d.MAC[5] {
+					case 1:
+						result.skipped = true
+					case 3:
+						result.err = errors.New("upload failed")
+					}
+					return result
+				},
+			}
+
+			out := &bytes.Buffer{}
+			failed := r.run(devices, out)
+
+			if updated != tt.wantUpdated {
+				t.Errorf("rollout.run() updated %d devices, want %d", updated, tt.wantUpdated)
+			}
+			if len(failed) != 1 {
+				t.Errorf("rollout.run() failed = %v, want 1 device", failed)
+			}
+			if stopped := strings.Contains(out.String(), "stopped rollout"); stopped != tt.wantStopped {
+				t.Errorf("rollout.run() stopped = %v, want %v", stopped, tt.wantStopped)
+			}
+
+			for _, want := range []string{
+				"5c:cf:7f:00:00:01  192.168.1.20  3.82.59 up to date\n",
+				"5c:cf:7f:00:00:02  192.168.1.21  3.82.59 -> 3.82.60\n",
+				"5c:cf:7f:00:00:03  192.168.1.22  error: upload failed\n",
+			} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("rollout.run() output %q does not contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateType(t *testing.T) {
+	tests := []struct {
+		types   string
+		want    mystrom.DeviceType
+		wantErr bool
+	}{
+		{types: "", wantErr: true},
+		{types: "switch", wantErr: true},
+		{types: "bulb,strip", wantErr: true},
+		{types: "switch eu", want: mystrom.DeviceTypeSwitchEU},
+		{types: "102", want: mystrom.DeviceTypeBulb},
+	}
+	for _, tt := range tests {
+		got, err := updateType(tt.types)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("updateType(%q) error = %v, wantErr %v", tt.types, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("updateType(%q) = %v, want %v", tt.types, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// groupConfig is the config file of the group command, e.g.
+//
+//	{"parallelism": 4, "groups": {"living-room": ["192.168.1.20", "192.168.1.21"]}}
+type groupConfig struct {
+	Parallelism int                 `json:"parallelism"`
+	Groups      map[string][]string `json:"groups"`
+}
+
+func defaultGroupConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "groups.json"
+	}
+	return filepath.Join(dir, "mystrom", "groups.json")
+}
+
+func loadGroupConfig(path string) (*groupConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading group config: %w", err)
+	}
+
+	config := groupConfig{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing group config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// group returns the Group with the given name and the addresses of its members.
+func (c *groupConfig) group(client *mystrom.Client, name string) (*mystrom.Group, []string, error) {
+	addresses, ok := c.Groups[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("group '%s' is not defined", name)
+	}
+	if len(addresses) == 0 {
+		return nil, nil, fmt.Errorf("group '%s' has no members", name)
+	}
+
+	g := &mystrom.Group{Parallelism: c.Parallelism}
+	for _, address := range addresses {
+		u, err := url.Parse("http://" + address)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing url for switch %s: %w", address, err)
+		}
+		g.Members = append(g.Members, client.NewSwitch(u))
+	}
+	return g, addresses, nil
+}
+
+type groupCommand struct {
+	args int
+	run  func(ctx context.Context, g *mystrom.Group, args []string) ([]mystrom.GroupResult, error)
+}
+
+var groupCommands = map[string]groupCommand{
+	"on": {run: func(ctx context.Context, g *mystrom.Group, _ []string) ([]mystrom.GroupResult, error) {
+		return g.On(ctx)
+	}},
+	"off": {run: func(ctx context.Context, g *mystrom.Group, _ []string) ([]mystrom.GroupResult, error) {
+		return g.Off(ctx)
+	}},
+	"toggle": {run: func(ctx context.Context, g *mystrom.Group, _ []string) ([]mystrom.GroupResult, error) {
+		return g.Toggle(ctx)
+	}},
+	"report": {run: func(ctx context.Context, g *mystrom.Group, _ []string) ([]mystrom.GroupResult, error) {
+		return g.Report(ctx)
+	}},
+	"power-cycle": {args: 1, run: func(ctx context.Context, g *mystrom.Group, args []string) ([]mystrom.GroupResult, error) {
+		wait, err := parseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return g.PowerCycle(ctx, wait)
+	}},
+}
+
+func groupResults(addresses []string, results []mystrom.GroupResult) ([]switchResult, int) {
+	code := 0
+	out := make([]switchResult, 0, len(results))
+	for i, r := range results {
+		result := switchResult{Address: addresses[i]}
+		if r.Report != nil {
+			result.Result = r.Report
+		}
+		if r.Err != nil {
+			result.Error = r.Err.Error()
+			if c := classify(r.Err); c > code {
+				code = c
+			}
+		}
+		out = append(out, result)
+	}
+	return out, code
+}
+
+func group(args []string) error {
+	flags := flag.NewFlagSet("group", flag.ContinueOnError)
+	configPath := flags.String("config", defaultGroupConfigPath(), "group config file")
+	jsonOutput := flags.Bool("json", false, "print results as JSON")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout for the whole group")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() < 2 {
+		return errors.New("group requires a name and a command")
+	}
+	name, command, commandArgs := flags.Arg(0), flags.Arg(1), flags.Args()[2:]
+
+	cmd, ok := groupCommands[command]
+	if !ok {
+		return fmt.Errorf("group command '%s' is not defined", command)
+	}
+	if len(commandArgs) != cmd.args {
+		return fmt.Errorf("group command %s requires %d arguments, got %d", command, cmd.args, len(commandArgs))
+	}
+
+	config, err := loadGroupConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	g, addresses, err := config.group(mystrom.NewClient(), name)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	results, err := cmd.run(ctx, g, commandArgs)
+	if results == nil && err != nil {
+		return err
+	}
+
+	out, code := groupResults(addresses, results)
+	err = printSwitchResults(out, *jsonOutput)
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return &exitError{code: code, err: fmt.Errorf("group %s %s failed", name, command)}
+	}
+	return nil
+}
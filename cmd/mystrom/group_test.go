@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func TestGroupConfig(t *testing.T) {
+	sim := mystromtest.NewSwitch(mystromtest.SwitchConfig{})
+	ok := httptest.NewServer(sim)
+	defer ok.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	okAddress := strings.TrimPrefix(ok.URL, "http://")
+	brokenAddress := strings.TrimPrefix(broken.URL, "http://")
+
+	path := filepath.Join(t.TempDir(), "groups.json")
+	data := `{"parallelism": 2, "groups": {"kitchen": ["` + okAddress + `", "` + brokenAddress + `"], "empty": []}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadGroupConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := mystrom.NewClient()
+	if _, _, err := config.group(client, "garden"); err == nil {
+		t.Error("groupConfig.group() expected error for unknown group")
+	}
+	if _, _, err := config.group(client, "empty"); err == nil {
+		t.Error("groupConfig.group() expected error for empty group")
+	}
+
+	g, addresses, err := config.group(client, "kitchen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Parallelism != 2 || len(g.Members) != 2 {
+		t.Errorf("groupConfig.group() = %+v", g)
+	}
+
+	results, err := groupCommands["on"].run(context.Background(), g, nil)
+	if err == nil {
+		t.Error("expected group error")
+	}
+
+	out, code := groupResults(addresses, results)
+	if code != exitStatus {
+		t.Errorf("groupResults() code = %d, want %d", code, exitStatus)
+	}
+	if out[0].Address != okAddress || out[0].Error != "" || out[1].Address != brokenAddress || out[1].Error == "" {
+		t.Errorf("groupResults() = %+v", out)
+	}
+	if !sim.Relay() {
+		t.Error("expected relay to be on")
+	}
+}
@@ -1,82 +1,79 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
-	"net/url"
 	"os"
-
-	"thde.io/mystrom"
 )
 
 const (
 	DiscoverCommand = "discover"
 )
 
+const (
+	exitFailure = 1 // usage or any other error
+	exitNetwork = 2 // a device could not be reached
+	exitStatus  = 3 // a device responded with an error status
+)
+
+// exitError carries the exit code of a failed command.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
 func run() error {
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "%s commands:\n", os.Args[0])
-		fmt.Fprintf(flag.CommandLine.Output(), " discover - discover local mystrom devices\n")
-		fmt.Fprintf(flag.CommandLine.Output(), " switch [address] (on|off|toggle) - control switch\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " discover [-format table|json|ndjson|csv] [-once] [-timeout duration] [-type type] - discover local mystrom devices\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " switch [-json] address... (on|off|toggle|report|temperature|reboot|power-cycle duration|timer mode duration) - control switches\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " group [-config file] [-json] name (on|off|toggle|report|power-cycle duration) - control a group of switches\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " exporter [-target [name=]address]... [-discover] - serve prometheus metrics\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " firmware status|update [-type type] [-file image] [-version version] [-batch n] [-pause duration] - inspect and update the firmware of discovered devices\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " provision [-scan] [-ssid ssid] [-ip ip -mask mask -gateway ip] [-count n] - connect devices in access point mode to a network\n")
+		fmt.Fprintf(flag.CommandLine.Output(), " simulate [-count n] [-type type] [-address address] - send discovery beacons of simulated devices\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 
 	switch flag.Arg(0) {
 	case "discover":
-		return discover(":7979")
+		return discover(flag.Args()[1:])
 	case "switch":
 		return sw(flag.Args()[1:])
+	case "group":
+		return group(flag.Args()[1:])
+	case "exporter":
+		return exporter(flag.Args()[1:])
+	case "firmware":
+		return firmware(flag.Args()[1:])
+	case "provision":
+		return provision(flag.Args()[1:])
+	case "simulate":
+		return simulate(flag.Args()[1:])
 	default:
 		flag.Usage()
 		return nil
 	}
 }
 
-func discover(address string) error {
-	log.Printf("listening on %s", address)
-
-	for {
-		discover := mystrom.Discover{Address: address}
-		device, err := discover.Device(context.Background())
-		if err != nil {
-			return fmt.Errorf("error discovering devices: %w", err)
-		}
-
-		log.Printf("%s: %+v", address, device)
-	}
-}
-
-func sw(args []string) error {
-	if len(args) < 2 {
-		return fmt.Errorf("switch requires address and command arguments")
-	}
-
-	u, err := url.Parse("http://" + args[0])
-	if err != nil {
-		return fmt.Errorf("error parsing url for switch %s: %w", args[0], err)
-	}
-
-	sw := mystrom.NewSwitch(u)
-
-	switch args[1] {
-	case "on":
-		return sw.On(context.Background())
-	case "off":
-		return sw.On(context.Background())
-	case "toggle":
-		return sw.On(context.Background())
-	default:
-		return fmt.Errorf("argument '%s' is not defined", args[0])
-	}
-}
-
 func main() {
 	err := run()
 	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// parseIP parses an optional IP address flag.
+func parseIP(name, s string) (net.IP, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid %s '%s'", name, s)
+	}
+	return ip, nil
+}
+
+// prompt prints msg and blocks until a line was read.
+func prompt(in *bufio.Reader, out io.Writer, msg string) error {
+	fmt.Fprintf(out, "%s and press enter ", msg)
+	_, err := in.ReadString('\n')
+	return err
+}
+
+// passwordEnv is the environment variable holding the password of the network.
+const passwordEnv = "MYSTROM_WIFI_PASSWORD"
+
+// wifiPassword returns the password of the network from the -password flag
+// if set, from passwordEnv or else reads it from in.
+func wifiPassword(password string, set bool, in *bufio.Reader, out io.Writer) (string, error) {
+	if set {
+		return password, nil
+	}
+	if password, ok := os.LookupEnv(passwordEnv); ok {
+		return password, nil
+	}
+
+	fmt.Fprint(out, "password of the network, empty if none: ")
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("error reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// connectDevice configures the device in access point mode at ap and
+// returns its MAC address.
+func connectDevice(ctx context.Context, client *mystrom.Client, ap *url.URL, config mystrom.WiFiConfig) (net.HardwareAddr, error) {
+	info, err := client.Info(ctx, ap)
+	if err != nil {
+		return nil, fmt.Errorf("error reading device info: %w", err)
+	}
+
+	err = client.ConnectWiFi(ctx, ap, config)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting %s to %s: %w", info.MAC, config.SSID, err)
+	}
+
+	return info.MAC, nil
+}
+
+func printNetworks(w io.Writer, networks []mystrom.WiFiNetwork) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SSID\tSIGNAL")
+	for _, n := range networks {
+		fmt.Fprintf(tw, "%s\t%d dBm\n", n.SSID, n.Signal)
+	}
+	return tw.Flush()
+}
+
+func provision(args []string) error {
+	flags := flag.NewFlagSet("provision", flag.ContinueOnError)
+	apAddress := flags.String("ap", mystrom.AccessPointURL.Host, "address of the device in access point mode")
+	scan := flags.Bool("scan", false, "list the networks visible to the device and exit")
+	ssid := flags.String("ssid", "", "network to connect the devices to")
+	password := flags.String("password", "", "password of the network, visible to other users, prefer $"+passwordEnv+" or the prompt")
+	ipAddress := flags.String("ip", "", "static ip address, dhcp is used if empty")
+	maskAddress := flags.String("mask", "", "netmask for a static ip address")
+	gatewayAddress := flags.String("gateway", "", "gateway for a static ip address")
+	dnsAddress := flags.String("dns", "", "dns server for a static ip address")
+	count := flags.Int("count", 1, "number of devices to provision one after another")
+	wait := flags.Bool("wait", true, "wait until the devices are online on the network")
+	discoverAddress := flags.String("discover-address", ":7979", "address to listen for discovery beacons")
+	timeout := flags.Duration("timeout", 2*time.Minute, "timeout per device")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	ap, err := url.Parse("http://" + *apAddress)
+	if err != nil {
+		return fmt.Errorf("error parsing url for access point %s: %w", *apAddress, err)
+	}
+
+	client := mystrom.NewClient()
+
+	if *scan {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		networks, err := client.ScanWiFi(ctx, ap)
+		if err != nil {
+			return &exitError{code: classify(err), err: fmt.Errorf("error scanning networks: %w", err)}
+		}
+		return printNetworks(os.Stdout, networks)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	config := mystrom.WiFiConfig{SSID: *ssid}
+	for _, f := range []struct {
+		name, value string
+		ip          *net.IP
+	}{
+		{"ip", *ipAddress, &config.IP},
+		{"mask", *maskAddress, &config.Mask},
+		{"gateway", *gatewayAddress, &config.Gateway},
+		{"dns", *dnsAddress, &config.DNS},
+	} {
+		*f.ip, err = parseIP(f.name, f.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return err
+	}
+	if config.IP != nil && *count > 1 {
+		return fmt.Errorf("a static ip can only be used for a single device")
+	}
+
+	passwordSet := false
+	flags.Visit(func(f *flag.Flag) {
+		passwordSet = passwordSet || f.Name == "password"
+	})
+	config.Password, err = wifiPassword(*password, passwordSet, in, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	macs := make([]net.HardwareAddr, 0, *count)
+	for i := 1; i <= *count; i++ {
+		if *count > 1 {
+			err = prompt(in, os.Stderr, fmt.Sprintf("join the access point of device %d/%d", i, *count))
+			if err != nil {
+				return err
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		mac, err := connectDevice(ctx, client, ap, config)
+		cancel()
+		if err != nil {
+			return &exitError{code: classify(err), err: err}
+		}
+
+		fmt.Fprintf(os.Stderr, "connected %s to %s\n", mac, config.SSID)
+		macs = append(macs, mac)
+	}
+
+	if !*wait {
+		return nil
+	}
+
+	err = prompt(in, os.Stderr, fmt.Sprintf("join the network %s", config.SSID))
+	if err != nil {
+		return err
+	}
+
+	d := &mystrom.Discover{Address: *discoverAddress}
+	for _, mac := range macs {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		u, info, err := client.WaitOnline(ctx, d, mac)
+		cancel()
+		if err != nil {
+			return &exitError{code: exitNetwork, err: err}
+		}
+
+		fmt.Printf("%s\t%s\t%s\n", mac, u.Host, info.SSID)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func TestParseIP(t *testing.T) {
+	ip, err := parseIP("ip", "")
+	if ip != nil || err != nil {
+		t.Errorf("parseIP() = %v, %v, want nil", ip, err)
+	}
+
+	ip, err = parseIP("ip", "192.168.1.20")
+	if err != nil || !ip.Equal(net.IPv4(192, 168, 1, 20)) {
+		t.Errorf("parseIP() = %v, %v, want 192.168.1.20", ip, err)
+	}
+
+	if _, err := parseIP("ip", "192.168.1"); err == nil {
+		t.Error("parseIP() expected error for invalid address")
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := prompt(bufio.NewReader(strings.NewReader("\n")), out, "join the network Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "join the network Home and press enter "; out.String() != want {
+		t.Errorf("prompt() printed %q, want %q", out.String(), want)
+	}
+}
+
+func TestWiFiPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		set      bool
+		env      string
+		input    string
+		want     string
+	}{
+		{name: "flag", password: "flag", set: true, env: "env", want: "flag"},
+		{name: "empty flag", set: true, env: "env", want: ""},
+		{name: "env", env: "env", input: "input\n", want: "env"},
+		{name: "prompt", input: "input\n", want: "input"},
+		{name: "prompt without newline", input: "input", want: "input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(passwordEnv, tt.env)
+			}
+
+			got, err := wifiPassword(tt.password, tt.set, bufio.NewReader(strings.NewReader(tt.input)), &bytes.Buffer{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("wifiPassword() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectDevice(t *testing.T) {
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x09}
+	device := mystromtest.NewSwitch(mystromtest.SwitchConfig{MAC: mac})
+
+	connected := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/connect" {
+			connected = true
+			return
+		}
+		device.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	ap, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := connectDevice(context.Background(), mystrom.NewClient(), ap, mystrom.WiFiConfig{SSID: "Home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != mac.String() {
+		t.Errorf("connectDevice() = %s, want %s", got, mac)
+	}
+	if !connected {
+		t.Error("expected connect request")
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+// deviceTypes lists all known device types.
+var deviceTypes = []mystrom.DeviceType{
+	mystrom.DeviceTypeBulb,
+	mystrom.DeviceTypeButtonPlus1stGeneration,
+	mystrom.DeviceTypeButtonSmall,
+	mystrom.DeviceTypeLEDStrip,
+	mystrom.DeviceTypeSwitchCH,
+	mystrom.DeviceTypeSwitchEU,
+	mystrom.DeviceTypeMotionSensor,
+	mystrom.DeviceTypeGateway,
+	mystrom.DeviceTypeSTECCO,
+	mystrom.DeviceTypeButtonPlus2ndGeneration,
+	mystrom.DeviceTypeSwitchZero,
+}
+
+// parseDeviceTypes resolves comma separated device types by number or name,
+// a name matches every type containing it, e.g. "switch" all switches.
+func parseDeviceTypes(s string) ([]mystrom.DeviceType, error) {
+	var types []mystrom.DeviceType
+	for _, name := range strings.Split(s, ",") {
+		if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+			types = append(types, mystrom.DeviceType(n))
+			continue
+		}
+
+		filter := typeFilter{name}
+		found := false
+		for _, t := range deviceTypes {
+			if filter.match(t) {
+				types = append(types, t)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("device type '%s' is not defined", name)
+		}
+	}
+
+	return types, nil
+}
+
+// simulatedDevices returns count devices with consecutive MAC addresses
+// starting at mac, cycling through types.
+func simulatedDevices(count int, mac net.HardwareAddr, types []mystrom.DeviceType) []mystrom.Device {
+	devices := make([]mystrom.Device, 0, count)
+	for i := 0; i < count; i++ {
+		devices = append(devices, mystrom.Device{
+			MAC:  append(net.HardwareAddr(nil), mac...),
+			Type: types[i%len(types)],
+		})
+
+		for j := len(mac) - 1; j >= 0; j-- {
+			mac[j]++
+			if mac[j] != 0 {
+				break
+			}
+		}
+	}
+
+	return devices
+}
+
+func simulate(args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	address := flags.String("address", "255.255.255.255:7979", "address to send discovery beacons to")
+	count := flags.Int("count", 1, "number of devices to simulate")
+	types := flags.String("type", "switch", "comma separated device types to simulate, by number or name")
+	firstMAC := flags.String("mac", "5c:cf:7f:f0:00:01", "MAC address of the first device, following devices count up")
+	interval := flags.Duration("interval", mystrom.BeaconInterval, "interval between beacons")
+	cloud := flags.Bool("cloud", false, "set the cloud flag")
+	registered := flags.Bool("registered", false, "set the registered flag")
+	meshChild := flags.Bool("mesh-child", false, "set the mesh child flag")
+	timeout := flags.Duration("timeout", 0, "stop simulating after this duration")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+
+	mac, err := net.ParseMAC(*firstMAC)
+	if err != nil {
+		return fmt.Errorf("invalid mac address '%s': %w", *firstMAC, err)
+	}
+
+	simulated, err := parseDeviceTypes(*types)
+	if err != nil {
+		return err
+	}
+
+	devices := simulatedDevices(*count, mac, simulated)
+	for i := range devices {
+		devices[i].Cloud = *cloud
+		devices[i].Registered = *registered
+		devices[i].MeshChild = *meshChild
+
+		log.Printf("simulating %s %s", devices[i].Type, devices[i].MAC)
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	beacons := mystromtest.Beacons{
+		Address:  *address,
+		Interval: *interval,
+		Devices:  devices,
+	}
+	err = beacons.Run(ctx)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("error sending beacons: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func TestParseDeviceTypes(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []mystrom.DeviceType
+		wantErr bool
+	}{
+		{s: "107", want: []mystrom.DeviceType{mystrom.DeviceTypeSwitchEU}},
+		{s: "bulb,strip", want: []mystrom.DeviceType{mystrom.DeviceTypeBulb, mystrom.DeviceTypeLEDStrip}},
+		{s: "switch", want: []mystrom.DeviceType{mystrom.DeviceTypeSwitchCH, mystrom.DeviceTypeSwitchEU, mystrom.DeviceTypeSwitchZero}},
+		{s: "toaster", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseDeviceTypes(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDeviceTypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDeviceTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulatedDevices(t *testing.T) {
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0xf0, 0x00, 0xff}
+	types := []mystrom.DeviceType{mystrom.DeviceTypeSwitchEU, mystrom.DeviceTypeBulb}
+
+	got := simulatedDevices(3, mac, types)
+	want := []mystrom.Device{
+		{MAC: net.HardwareAddr{0x5c, 0xcf, 0x7f, 0xf0, 0x00, 0xff}, Type: mystrom.DeviceTypeSwitchEU},
+		{MAC: net.HardwareAddr{0x5c, 0xcf, 0x7f, 0xf0, 0x01, 0x00}, Type: mystrom.DeviceTypeBulb},
+		{MAC: net.HardwareAddr{0x5c, 0xcf, 0x7f, 0xf0, 0x01, 0x01}, Type: mystrom.DeviceTypeSwitchEU},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("simulatedDevices() = %v, want %v", got, want)
+	}
+}
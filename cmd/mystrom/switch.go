@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// classify returns the exit code for an error returned by a device.
+func classify(err error) int {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, mystrom.ErrStatus):
+		return exitStatus
+	case errors.As(err, &netErr):
+		return exitNetwork
+	default:
+		return exitFailure
+	}
+}
+
+type switchCommand struct {
+	args int
+	run  func(ctx context.Context, sw *mystrom.Switch, args []string) (interface{}, error)
+}
+
+var switchCommands = map[string]switchCommand{
+	"on": {run: func(ctx context.Context, sw *mystrom.Switch, _ []string) (interface{}, error) {
+		return nil, sw.On(ctx)
+	}},
+	"off": {run: func(ctx context.Context, sw *mystrom.Switch, _ []string) (interface{}, error) {
+		return nil, sw.Off(ctx)
+	}},
+	"toggle": {run: func(ctx context.Context, sw *mystrom.Switch, _ []string) (interface{}, error) {
+		return nil, sw.Toggle(ctx)
+	}},
+	"report": {run: func(ctx context.Context, sw *mystrom.Switch, _ []string) (interface{}, error) {
+		return sw.Report(ctx)
+	}},
+	"temperature": {run: func(ctx context.Context, sw *mystrom.Switch, _ []string) (interface{}, error) {
+		return sw.Temperature(ctx)
+	}},
+	"reboot": {run: func(ctx context.Context, sw *mystrom.Switch, _ []string) (interface{}, error) {
+		return nil, sw.Reboot(ctx)
+	}},
+	"power-cycle": {args: 1, run: func(ctx context.Context, sw *mystrom.Switch, args []string) (interface{}, error) {
+		wait, err := parseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return nil, sw.PowerCycle(ctx, wait)
+	}},
+	"timer": {args: 2, run: func(ctx context.Context, sw *mystrom.Switch, args []string) (interface{}, error) {
+		d, err := parseDuration(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return nil, sw.Timer(ctx, mystrom.SwitchTimerMode(args[0]), d)
+	}},
+}
+
+// parseDuration parses durations like 1m30s or plain seconds.
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(s); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s': %w", s, err)
+	}
+	return d, nil
+}
+
+// splitSwitchArgs splits args into addresses, the command and its arguments.
+func splitSwitchArgs(args []string) (addresses []string, command string, commandArgs []string, err error) {
+	for i, arg := range args {
+		if _, ok := switchCommands[arg]; ok {
+			addresses, command, commandArgs = args[:i], arg, args[i+1:]
+			break
+		}
+	}
+
+	if command == "" {
+		return nil, "", nil, fmt.Errorf("switch requires a command")
+	}
+	if len(addresses) == 0 {
+		return nil, "", nil, fmt.Errorf("switch requires at least one address")
+	}
+	if want := switchCommands[command].args; len(commandArgs) != want {
+		return nil, "", nil, fmt.Errorf("switch command %s requires %d arguments, got %d", command, want, len(commandArgs))
+	}
+
+	return addresses, command, commandArgs, nil
+}
+
+type switchResult struct {
+	Address string      `json:"address"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+func sw(args []string) error {
+	flags := flag.NewFlagSet("switch", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print results as JSON")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout per switch")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	addresses, command, commandArgs, err := splitSwitchArgs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	client := mystrom.NewClient()
+	results := make([]switchResult, 0, len(addresses))
+	code := 0
+
+	for _, address := range addresses {
+		result := switchResult{Address: address}
+
+		u, err := url.Parse("http://" + address)
+		if err != nil {
+			err = fmt.Errorf("error parsing url for switch %s: %w", address, err)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			result.Result, err = switchCommands[command].run(ctx, client.NewSwitch(u), commandArgs)
+			cancel()
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+			if c := classify(err); c > code {
+				code = c
+			}
+		}
+		results = append(results, result)
+	}
+
+	err = printSwitchResults(results, *jsonOutput)
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return &exitError{code: code, err: fmt.Errorf("switch %s failed", command)}
+	}
+	return nil
+}
+
+// formatResult formats the result of a switch command for humans.
+func formatResult(result interface{}) string {
+	switch r := result.(type) {
+	case *mystrom.SwitchReport:
+		relay := "off"
+		if r.Relay {
+			relay = "on"
+		}
+		s := fmt.Sprintf("relay %s, power %.1f W, temperature %.1f °C", relay, r.Power, r.Temperature)
+		if r.BootID != "" || r.TimeSinceBoot > 0 {
+			s += fmt.Sprintf(", energy %.0f Ws in %s since boot", r.EnergySinceBoot, r.Uptime())
+		}
+		return s
+	case *mystrom.SwitchTemperature:
+		return fmt.Sprintf("measured %.1f °C, compensation %.1f °C, compensated %.1f °C", r.Measured, r.Compensation, r.Compensated)
+	default:
+		return fmt.Sprintf("%+v", result)
+	}
+}
+
+func printSwitchResults(results []switchResult, jsonOutput bool) error {
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(os.Stderr, "%s: %s\n", r.Address, r.Error)
+		case r.Result != nil:
+			fmt.Printf("%s: %s\n", r.Address, formatResult(r.Result))
+		default:
+			fmt.Printf("%s: ok\n", r.Address)
+		}
+	}
+	return nil
+}
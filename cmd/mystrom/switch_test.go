@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+func TestSplitSwitchArgs(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantAddresses []string
+		wantCommand   string
+		wantArgs      []string
+		wantErr       bool
+	}{
+		{"single", []string{"192.168.1.20", "on"}, []string{"192.168.1.20"}, "on", []string{}, false},
+		{"multiple", []string{"192.168.1.20", "192.168.1.21", "report"}, []string{"192.168.1.20", "192.168.1.21"}, "report", []string{}, false},
+		{"power cycle", []string{"192.168.1.20", "power-cycle", "30s"}, []string{"192.168.1.20"}, "power-cycle", []string{"30s"}, false},
+		{"timer", []string{"192.168.1.20", "timer", "off", "5m"}, []string{"192.168.1.20"}, "timer", []string{"off", "5m"}, false},
+		{"missing address", []string{"on"}, nil, "", nil, true},
+		{"missing command", []string{"192.168.1.20"}, nil, "", nil, true},
+		{"missing argument", []string{"192.168.1.20", "power-cycle"}, nil, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses, command, args, err := splitSwitchArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitSwitchArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(addresses, tt.wantAddresses) || command != tt.wantCommand || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("splitSwitchArgs() = %v, %v, %v", addresses, command, args)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30", 30 * time.Second, false},
+		{"1m30s", 90 * time.Second, false},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseDuration() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{"status", failing.URL, exitStatus},
+		{"network", gone.URL, exitNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = switchCommands["toggle"].run(context.Background(), mystrom.NewSwitch(u), nil)
+			if got := classify(err); got != tt.want {
+				t.Errorf("classify(%v) = %d, want %d", err, got, tt.want)
+			}
+			if got := exitCode(&exitError{code: tt.want, err: err}); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		want   string
+	}{
+		{
+			name:   "report",
+			result: &mystrom.SwitchReport{Power: 42.5, Relay: true, Temperature: 22},
+			want:   "relay on, power 42.5 W, temperature 22.0 °C",
+		},
+		{
+			name:   "report with counters",
+			result: &mystrom.SwitchReport{BootID: "a", EnergySinceBoot: 1200, TimeSinceBoot: 90},
+			want:   "relay off, power 0.0 W, temperature 0.0 °C, energy 1200 Ws in 1m30s since boot",
+		},
+		{
+			name:   "temperature",
+			result: &mystrom.SwitchTemperature{Measured: 25, Compensation: 3, Compensated: 22},
+			want:   "measured 25.0 °C, compensation 3.0 °C, compensated 22.0 °C",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatResult(tt.result); got != tt.want {
+				t.Errorf("formatResult() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
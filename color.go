@@ -0,0 +1,123 @@
+package mystrom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColorMode defines how the color of a Bulb or LED Strip is interpreted.
+type ColorMode string
+
+const (
+	ColorModeHSV  ColorMode = "hsv"
+	ColorModeRGB  ColorMode = "rgb"
+	ColorModeMono ColorMode = "mono"
+)
+
+// HSV represents a color in the hue, saturation and value color space.
+type HSV struct {
+	Hue        int // 0 - 360
+	Saturation int // 0 - 100
+	Value      int // 0 - 100
+}
+
+func (h HSV) String() string {
+	return fmt.Sprintf("%d;%d;%d", h.Hue, h.Saturation, h.Value)
+}
+
+func parseHSV(s string) (h HSV, err error) {
+	parts := strings.Split(s, ";")
+	if len(parts) != 3 {
+		return h, fmt.Errorf("invalid hsv color '%s'", s)
+	}
+
+	values := make([]int, len(parts))
+	for i, p := range parts {
+		values[i], err = strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return h, fmt.Errorf("invalid hsv color '%s': %w", s, err)
+		}
+	}
+
+	return HSV{Hue: values[0], Saturation: values[1], Value: values[2]}, nil
+}
+
+// RGBW represents a color with red, green, blue and white channels.
+type RGBW struct {
+	R, G, B, W uint8
+}
+
+// String returns the color in the WWRRGGBB hex notation used by the devices.
+func (c RGBW) String() string {
+	return fmt.Sprintf("%02X%02X%02X%02X", c.W, c.R, c.G, c.B)
+}
+
+func parseRGBW(s string) (c RGBW, err error) {
+	if len(s) == 6 {
+		s = "00" + s
+	}
+	if len(s) != 8 {
+		return c, fmt.Errorf("invalid rgbw color '%s'", s)
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return c, fmt.Errorf("invalid rgbw color '%s': %w", s, err)
+	}
+
+	return RGBW{W: uint8(v >> 24), R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// withBrightness scales all channels so the brightest one matches the
+// brightness (0 - 100), keeping the ratio between the channels.
+func (c RGBW) withBrightness(brightness int) RGBW {
+	target := 255 * brightness / 100
+	if target < 0 {
+		target = 0
+	}
+	if target > 255 {
+		target = 255
+	}
+
+	current := int(c.W)
+	for _, ch := range []uint8{c.R, c.G, c.B} {
+		if int(ch) > current {
+			current = int(ch)
+		}
+	}
+	if current == 0 {
+		return RGBW{W: uint8(target)}
+	}
+
+	scale := func(ch uint8) uint8 { return uint8(int(ch) * target / current) }
+	return RGBW{R: scale(c.R), G: scale(c.G), B: scale(c.B), W: scale(c.W)}
+}
+
+// Mono represents a white color with a color temperature and a brightness.
+type Mono struct {
+	Temperature int // 1 (warm) - 18 (cold)
+	Brightness  int // 0 - 100
+}
+
+func (m Mono) String() string {
+	return fmt.Sprintf("%d;%d", m.Temperature, m.Brightness)
+}
+
+func parseMono(s string) (m Mono, err error) {
+	parts := strings.Split(s, ";")
+	if len(parts) != 2 {
+		return m, fmt.Errorf("invalid mono color '%s'", s)
+	}
+
+	m.Temperature, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return m, fmt.Errorf("invalid mono color '%s': %w", s, err)
+	}
+	m.Brightness, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return m, fmt.Errorf("invalid mono color '%s': %w", s, err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,82 @@
+package mystrom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// deviceAPI talks to devices which expose their state on /api/v1/device
+// and accept changes on /api/v1/device/{MAC}, like the Bulb or LED Strip.
+type deviceAPI struct {
+	baseURL *url.URL
+	client  *Client
+
+	mu  sync.Mutex
+	mac string
+}
+
+// state decodes the state of the device into v and returns its MAC address.
+func (d *deviceAPI) state(ctx context.Context, v interface{}) (string, error) {
+	req, err := d.client.newRequest(ctx, d.baseURL, http.MethodGet, "api/v1/device", nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	devices := map[string]json.RawMessage{}
+	_, err = d.client.doJSON(req, &devices)
+	if err != nil {
+		return "", err
+	}
+
+	for mac, raw := range devices {
+		err = json.Unmarshal(raw, v)
+		if err != nil {
+			return "", fmt.Errorf("error decoding state of %s: %w", mac, err)
+		}
+
+		d.mu.Lock()
+		d.mac = mac
+		d.mu.Unlock()
+
+		return mac, nil
+	}
+
+	return "", fmt.Errorf("no device found in response")
+}
+
+func (d *deviceAPI) deviceMAC(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	mac := d.mac
+	d.mu.Unlock()
+	if mac != "" {
+		return mac, nil
+	}
+
+	mac, err := d.state(ctx, &struct{}{})
+	if err != nil {
+		return "", fmt.Errorf("error resolving mac address: %w", err)
+	}
+
+	return mac, nil
+}
+
+func (d *deviceAPI) set(ctx context.Context, params url.Values) error {
+	mac, err := d.deviceMAC(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := d.client.newRequest(ctx, d.baseURL, http.MethodPost, "api/v1/device/"+mac, params, nil)
+	if err != nil {
+		return err
+	}
+	if params.Get("action") == "toggle" {
+		req = nonIdempotent(req)
+	}
+
+	return d.client.doDiscard(req)
+}
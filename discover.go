@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type DeviceType byte
@@ -62,6 +65,27 @@ type Device struct {
 	MeshChild  bool
 }
 
+// URL returns the base URL of the HTTP API of the device.
+func (d Device) URL() (*url.URL, error) {
+	if d.Address == nil {
+		return nil, fmt.Errorf("device %s has no address", d.MAC)
+	}
+
+	host, _, err := net.SplitHostPort(d.Address.String())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing address %s: %w", d.Address, err)
+	}
+
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	return &url.URL{Scheme: "http", Host: host}, nil
+}
+
+// BeaconInterval is the interval in which devices send a discovery beacon.
+const BeaconInterval = 5 * time.Second
+
 type Discover struct {
 	// See func net.Dial for a description of the Address parameter.
 	Address string
@@ -69,18 +93,30 @@ type Discover struct {
 	// See net.ListenPacket for more details on the Network parameter.
 	Network      string
 	ListenConfig net.ListenConfig
+	// Expiry is the time without a beacon after which Devices reports a
+	// device as disappeared, defaults to three beacon intervals.
+	Expiry time.Duration
 }
 
-// Device blocks until a MyStrom device has been discovered.
-// Each device cyclically (every 5 seconds) sends a broadcast packet
-// using the UDP protocol to the address 255.255.255.255 and port 7979.
-func (d *Discover) Device(ctx context.Context) (Device, error) {
+func (d *Discover) listen(ctx context.Context) (net.PacketConn, error) {
 	address := defaultString(d.Address, ":7979")
 	network := defaultString(d.Network, "udp")
 
 	pc, err := d.ListenConfig.ListenPacket(ctx, network, address)
 	if err != nil {
-		return Device{}, fmt.Errorf("listen packet error for address %s, network %s: %w", address, network, err)
+		return nil, fmt.Errorf("listen packet error for address %s, network %s: %w", address, network, err)
+	}
+
+	return pc, nil
+}
+
+// Device blocks until a MyStrom device has been discovered.
+// Each device cyclically (every 5 seconds) sends a broadcast packet
+// using the UDP protocol to the address 255.255.255.255 and port 7979.
+func (d *Discover) Device(ctx context.Context) (Device, error) {
+	pc, err := d.listen(ctx)
+	if err != nil {
+		return Device{}, err
 	}
 	defer pc.Close()
 
@@ -93,6 +129,208 @@ func (d *Discover) Device(ctx context.Context) (Device, error) {
 	return parseDevicePayload(buf, addr)
 }
 
+// Each calls fn for every received beacon until ctx is canceled, keeping
+// the socket open in between. Invalid beacons are passed to fn as error
+// without stopping. Beacons are not deduplicated, see Devices for that.
+func (d *Discover) Each(ctx context.Context, fn func(Device, error)) error {
+	pc, err := d.listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	return read(ctx, pc, fn)
+}
+
+// read calls fn for every packet received on pc until ctx is canceled or
+// reading fails. pc is closed when read returns.
+func read(ctx context.Context, pc net.PacketConn, fn func(Device, error)) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		pc.Close()
+	}()
+
+	buf := make([]byte, 64)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("error reading packet: %w", err)
+		}
+
+		fn(parseDevicePayload(buf[:n], addr))
+	}
+}
+
+// DeviceEventType describes what happened to a discovered device.
+type DeviceEventType int
+
+const (
+	DeviceAppeared    DeviceEventType = iota + 1 // first beacon of a device
+	DeviceUpdated                                // address, type or flags of a device changed
+	DeviceDisappeared                            // no beacon within the expiry
+	DeviceError                                  // invalid beacon or read error
+)
+
+func (t DeviceEventType) String() string {
+	switch t {
+	case DeviceAppeared:
+		return "appeared"
+	case DeviceUpdated:
+		return "updated"
+	case DeviceDisappeared:
+		return "disappeared"
+	case DeviceError:
+		return "error"
+	default:
+		return fmt.Sprintf("%d", t)
+	}
+}
+
+// DeviceEvent is emitted by Devices.
+type DeviceEvent struct {
+	Type   DeviceEventType
+	Device Device
+	Err    error // set for DeviceError
+}
+
+// Devices keeps listening for beacons until ctx is canceled and emits an
+// event whenever a device appears, changes or disappears. Devices are
+// deduplicated by MAC address. Invalid beacons are emitted as DeviceError
+// events without stopping, the channel is closed once listening stopped.
+func (d *Discover) Devices(ctx context.Context) (<-chan DeviceEvent, error) {
+	pc, err := d.listen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.events(ctx, pc), nil
+}
+
+type beacon struct {
+	device Device
+	err    error
+}
+
+func (d *Discover) events(ctx context.Context, pc net.PacketConn) <-chan DeviceEvent {
+	expiry := d.Expiry
+	if expiry <= 0 {
+		expiry = 3 * BeaconInterval
+	}
+
+	beacons := make(chan beacon)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- read(ctx, pc, func(device Device, err error) {
+			select {
+			case beacons <- beacon{device: device, err: err}:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	events := make(chan DeviceEvent)
+	go func() {
+		defer close(events)
+
+		emit := func(e DeviceEvent) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		type entry struct {
+			device   Device
+			lastSeen time.Time
+		}
+		devices := map[string]entry{}
+
+		ticker := time.NewTicker(expiry / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-readErr:
+				if ctx.Err() == nil {
+					emit(DeviceEvent{Type: DeviceError, Err: err})
+				}
+				return
+			case b := <-beacons:
+				if b.err != nil {
+					if !emit(DeviceEvent{Type: DeviceError, Device: b.device, Err: b.err}) {
+						return
+					}
+					continue
+				}
+
+				key := b.device.MAC.String()
+				prev, known := devices[key]
+				devices[key] = entry{device: b.device, lastSeen: time.Now()}
+
+				var ok bool
+				switch {
+				case !known:
+					ok = emit(DeviceEvent{Type: DeviceAppeared, Device: b.device})
+				case !prev.device.equal(b.device):
+					ok = emit(DeviceEvent{Type: DeviceUpdated, Device: b.device})
+				default:
+					ok = true
+				}
+				if !ok {
+					return
+				}
+			case now := <-ticker.C:
+				for key, e := range devices {
+					if now.Sub(e.lastSeen) < expiry {
+						continue
+					}
+
+					delete(devices, key)
+					if !emit(DeviceEvent{Type: DeviceDisappeared, Device: e.device}) {
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return events
+}
+
+func (d Device) equal(o Device) bool {
+	return addrHost(d.Address) == addrHost(o.Address) &&
+		d.MAC.String() == o.MAC.String() &&
+		d.Type == o.Type &&
+		d.Cloud == o.Cloud &&
+		d.Registered == o.Registered &&
+		d.MeshChild == o.MeshChild
+}
+
+// addrHost returns the host of a, beacons may be sent from varying ports.
+func addrHost(a net.Addr) string {
+	if a == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(a.String())
+	if err != nil {
+		return a.String()
+	}
+	return host
+}
+
 func defaultString(s, def string) string {
 	if s != "" {
 		return s
@@ -108,7 +346,7 @@ func parseDevicePayload(buf []byte, addr net.Addr) (d Device, err error) {
 	}
 
 	d.Address = addr
-	d.MAC = net.HardwareAddr(buf[:6])
+	d.MAC = append(net.HardwareAddr(nil), buf[:6]...)
 	d.Type = DeviceType(buf[6])
 
 	status := buf[7]
@@ -118,3 +356,26 @@ func parseDevicePayload(buf []byte, addr net.Addr) (d Device, err error) {
 
 	return d, nil
 }
+
+// MarshalBinary encodes d as discovery beacon payload, the inverse of the
+// parsing done by Discover. The address of d is not part of the payload.
+func (d Device) MarshalBinary() ([]byte, error) {
+	if len(d.MAC) != 6 {
+		return nil, fmt.Errorf("invalid MAC address length: %d", len(d.MAC))
+	}
+
+	var status byte
+	if d.MeshChild {
+		status |= 1 << 0
+	}
+	if d.Registered {
+		status |= 1 << 1
+	}
+	if d.Cloud {
+		status |= 1 << 2
+	}
+
+	buf := make([]byte, 0, 8)
+	buf = append(buf, d.MAC...)
+	return append(buf, byte(d.Type), status), nil
+}
@@ -1,9 +1,12 @@
 package mystrom
 
 import (
+	"context"
 	"net"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_defaultString(t *testing.T) {
@@ -85,3 +88,125 @@ func Test_parseDevicePayload(t *testing.T) {
 		})
 	}
 }
+
+func TestDevice_MarshalBinary(t *testing.T) {
+	d := Device{
+		MAC:        net.HardwareAddr{0x01, 0x23, 0x45, 0x67, 0x89, 0xab},
+		Type:       DeviceTypeSwitchEU,
+		Registered: true,
+		Cloud:      true,
+	}
+
+	buf, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, byte(DeviceTypeSwitchEU), 0x06}; !reflect.DeepEqual(buf, want) {
+		t.Errorf("Device.MarshalBinary() = %x, want %x", buf, want)
+	}
+
+	got, err := parseDevicePayload(buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, d) {
+		t.Errorf("parseDevicePayload() = %v, want %v", got, d)
+	}
+
+	if _, err := (Device{MAC: net.HardwareAddr{0x01}}).MarshalBinary(); err == nil {
+		t.Error("Device.MarshalBinary() expected error for invalid MAC")
+	}
+}
+
+func TestDevice_URL(t *testing.T) {
+	tests := []struct {
+		name    string
+		address net.Addr
+		want    *url.URL
+		wantErr bool
+	}{
+		{"ipv4", &net.UDPAddr{IP: net.IPv4(192, 168, 1, 20), Port: 7979}, &url.URL{Scheme: "http", Host: "192.168.1.20"}, false},
+		{"ipv6", &net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: 7979}, &url.URL{Scheme: "http", Host: "[fe80::1]"}, false},
+		{"no address", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Device{Address: tt.address}.URL()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Device.URL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Device.URL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscover_events(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := Discover{Expiry: 150 * time.Millisecond}
+	events := d.events(ctx, pc)
+
+	mac := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab}
+	send := func(payload ...byte) {
+		t.Helper()
+		if _, err := conn.Write(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := func(want DeviceEventType) DeviceEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Type != want {
+				t.Fatalf("expected %s event, got %s: %+v", want, e.Type, e)
+			}
+			return e
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s event, got none", want)
+		}
+		return DeviceEvent{}
+	}
+
+	send(append(mac, byte(DeviceTypeSwitchEU), 0x00)...)
+	e := next(DeviceAppeared)
+	if e.Device.Type != DeviceTypeSwitchEU || e.Device.MAC.String() != "01:23:45:67:89:ab" {
+		t.Errorf("unexpected device %+v", e.Device)
+	}
+
+	send(append(mac, byte(DeviceTypeSwitchEU), 0x00)...)
+	send(0x01, 0x02)
+	next(DeviceError)
+
+	send(append(mac, byte(DeviceTypeSwitchEU), 0x04)...)
+	e = next(DeviceUpdated)
+	if !e.Device.Cloud {
+		t.Errorf("expected updated device to be connected to the cloud, got %+v", e.Device)
+	}
+
+	next(DeviceDisappeared)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected events to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected events to be closed")
+	}
+}
@@ -0,0 +1,327 @@
+// Package energy accumulates the energy consumption of myStrom Switches
+// by polling their reports and integrating the power over time.
+package energy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+const (
+	// wattSecondsPerKWh is the amount of watt seconds in one kilowatt hour.
+	wattSecondsPerKWh = 3.6e6
+
+	hourLayout  = "2006-01-02T15Z07:00" // formatted in UTC
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+// Reporter returns the current report of a Switch, it is implemented by mystrom.Switch.
+type Reporter interface {
+	Report(ctx context.Context) (*mystrom.SwitchReport, error)
+}
+
+// Totals holds the accumulated energy consumption in kWh. Hours are keyed
+// in UTC to stay unique across daylight saving time changes, days and months
+// in the Location of the Meter.
+type Totals struct {
+	Total   float64            `json:"total"`
+	Hourly  map[string]float64 `json:"hourly"`  // keyed by 2006-01-02T15Z
+	Daily   map[string]float64 `json:"daily"`   // keyed by 2006-01-02
+	Monthly map[string]float64 `json:"monthly"` // keyed by 2006-01
+	// Last is the last recorded report, it is used to account the energy
+	// consumed while the Meter was not running.
+	Last *Sample `json:"last,omitempty"`
+
+	loc *time.Location
+}
+
+// Sample is a report of a Switch taken at a point in time.
+type Sample struct {
+	At     time.Time            `json:"at"`
+	Report mystrom.SwitchReport `json:"report"`
+}
+
+func newTotals() Totals {
+	return Totals{
+		Hourly:  map[string]float64{},
+		Daily:   map[string]float64{},
+		Monthly: map[string]float64{},
+	}
+}
+
+func (t Totals) clone() Totals {
+	c := newTotals()
+	c.Total = t.Total
+	c.loc = t.loc
+	if t.Last != nil {
+		last := *t.Last
+		c.Last = &last
+	}
+	for k, v := range t.Hourly {
+		c.Hourly[k] = v
+	}
+	for k, v := range t.Daily {
+		c.Daily[k] = v
+	}
+	for k, v := range t.Monthly {
+		c.Monthly[k] = v
+	}
+	return c
+}
+
+func (t *Totals) add(at time.Time, kWh float64) {
+	t.Total += kWh
+	t.Hourly[at.UTC().Format(hourLayout)] += kWh
+	t.Daily[at.In(t.location()).Format(dayLayout)] += kWh
+	t.Monthly[at.In(t.location()).Format(monthLayout)] += kWh
+}
+
+func (t Totals) location() *time.Location {
+	if t.loc != nil {
+		return t.loc
+	}
+	return time.Local
+}
+
+// Hour returns the energy consumed in the hour containing t.
+func (t Totals) Hour(at time.Time) float64 { return t.Hourly[at.UTC().Format(hourLayout)] }
+
+// Day returns the energy consumed on the day containing t in the Location of the Meter.
+func (t Totals) Day(at time.Time) float64 { return t.Daily[at.In(t.location()).Format(dayLayout)] }
+
+// Month returns the energy consumed in the month containing t in the Location of the Meter.
+func (t Totals) Month(at time.Time) float64 {
+	return t.Monthly[at.In(t.location()).Format(monthLayout)]
+}
+
+// Meter polls Switches and accumulates their energy consumption per ID.
+type Meter struct {
+	// Interval between two reports, defaults to 10 seconds.
+	Interval time.Duration
+	// MaxGap is the longest interval which is interpolated from the power
+	// readings, defaults to 5 minutes. Longer gaps are only accounted if the
+	// Switch provides energy counters.
+	MaxGap time.Duration
+	// Location is used to assign energy to hourly, daily and monthly buckets,
+	// defaults to time.Local.
+	Location *time.Location
+	// Store persists the totals, it is optional.
+	Store Store
+	// OnError is called for every failed report or store operation, it is optional.
+	OnError func(id string, err error)
+
+	mu     sync.Mutex
+	totals map[string]*Totals
+}
+
+func (m *Meter) location() *time.Location {
+	if m.Location != nil {
+		return m.Location
+	}
+	return time.Local
+}
+
+func (m *Meter) newTotals() *Totals {
+	t := newTotals()
+	t.loc = m.location()
+	return &t
+}
+
+// Totals returns the accumulated totals of the Switch with the given ID.
+func (m *Meter) Totals(id string) Totals {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.totals[id]
+	if !ok {
+		return *m.newTotals()
+	}
+	return t.clone()
+}
+
+// All returns the accumulated totals of all Switches.
+func (m *Meter) All() map[string]Totals {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	all := make(map[string]Totals, len(m.totals))
+	for id, t := range m.totals {
+		all[id] = t.clone()
+	}
+	return all
+}
+
+// Load restores the totals and the last report of every Switch from the Store.
+func (m *Meter) Load(ctx context.Context) error {
+	if m.Store == nil {
+		return nil
+	}
+
+	all, err := m.Store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("error loading totals: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.totals = make(map[string]*Totals, len(all))
+	for id, t := range all {
+		t := t.clone()
+		t.loc = m.location()
+		m.totals[id] = &t
+	}
+	return nil
+}
+
+// Save persists the totals to the Store.
+func (m *Meter) Save(ctx context.Context) error {
+	if m.Store == nil {
+		return nil
+	}
+
+	err := m.Store.Save(ctx, m.All())
+	if err != nil {
+		return fmt.Errorf("error saving totals: %w", err)
+	}
+	return nil
+}
+
+// Record accounts a report of the Switch with the given ID taken at now.
+// The energy since the previous report, which may have been restored by
+// Load, is derived from the energy counters of the Switch if available,
+// otherwise the power is integrated.
+func (m *Meter) Record(id string, report mystrom.SwitchReport, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.totals == nil {
+		m.totals = map[string]*Totals{}
+	}
+	t, ok := m.totals[id]
+	if !ok {
+		t = m.newTotals()
+		m.totals[id] = t
+	}
+
+	prev := t.Last
+	t.Last = &Sample{At: now, Report: report}
+	if prev == nil {
+		return
+	}
+
+	// time.Time.Sub uses the monotonic clock if available, which makes
+	// the elapsed time immune to wall clock changes.
+	elapsed := now.Sub(prev.At)
+	if elapsed <= 0 {
+		return
+	}
+
+	ws, ok := m.energy(prev.Report, report, elapsed)
+	if !ok || ws <= 0 {
+		return
+	}
+
+	m.distribute(t, now, elapsed, ws/wattSecondsPerKWh)
+}
+
+// energy returns the energy in watt seconds consumed between two reports.
+func (m *Meter) energy(prev, cur mystrom.SwitchReport, elapsed time.Duration) (float64, bool) {
+	counters := cur.BootID != "" || cur.TimeSinceBoot > 0
+	if counters {
+		if cur.Rebooted(prev) {
+			// energy consumed between the previous report and the reboot is lost
+			return cur.EnergySinceBoot, true
+		}
+		if delta := cur.EnergySinceBoot - prev.EnergySinceBoot; delta >= 0 {
+			return delta, true
+		}
+	}
+
+	if elapsed > defaultDuration(m.MaxGap, 5*time.Minute) {
+		return 0, false
+	}
+
+	// trapezoidal integration of the power readings
+	return (prev.Power + cur.Power) / 2 * elapsed.Seconds(), true
+}
+
+// distribute splits kWh consumed during elapsed before now evenly across
+// the hours it spans.
+func (m *Meter) distribute(t *Totals, now time.Time, elapsed time.Duration, kWh float64) {
+	end := now.In(m.location())
+	start := end.Add(-elapsed)
+	for start.Before(end) {
+		next := start.Truncate(time.Hour).Add(time.Hour)
+		if next.After(end) {
+			next = end
+		}
+
+		t.add(start, kWh*float64(next.Sub(start))/float64(elapsed))
+		start = next
+	}
+}
+
+// Run polls the given Switches keyed by ID until ctx is canceled. The totals
+// are loaded from the Store before and saved after every polling round.
+func (m *Meter) Run(ctx context.Context, switches map[string]Reporter) error {
+	err := m.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(defaultDuration(m.Interval, 10*time.Second))
+	defer ticker.Stop()
+
+	for {
+		m.poll(ctx, switches)
+
+		err = m.Save(ctx)
+		if err != nil {
+			m.onError("", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Meter) poll(ctx context.Context, switches map[string]Reporter) {
+	var wg sync.WaitGroup
+	for id, sw := range switches {
+		wg.Add(1)
+		go func(id string, sw Reporter) {
+			defer wg.Done()
+
+			report, err := sw.Report(ctx)
+			if err != nil {
+				m.onError(id, err)
+				return
+			}
+
+			m.Record(id, *report, time.Now())
+		}(id, sw)
+	}
+	wg.Wait()
+}
+
+func (m *Meter) onError(id string, err error) {
+	if m.OnError != nil {
+		m.OnError(id, err)
+	}
+}
+
+func defaultDuration(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,239 @@
+package energy_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/energy"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMeter_Record(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type record struct {
+		report mystrom.SwitchReport
+		offset time.Duration
+	}
+	tests := []struct {
+		name    string
+		records []record
+		want    float64 // kWh
+	}{
+		{
+			name: "integrate power",
+			records: []record{
+				{mystrom.SwitchReport{Power: 1000}, 0},
+				{mystrom.SwitchReport{Power: 1000}, time.Minute},
+				{mystrom.SwitchReport{Power: 2000}, 2 * time.Minute},
+			},
+			want: 1000.0/60/1000 + 1500.0/60/1000,
+		},
+		{
+			name: "skip long gap without counters",
+			records: []record{
+				{mystrom.SwitchReport{Power: 1000}, 0},
+				{mystrom.SwitchReport{Power: 1000}, time.Hour},
+			},
+			want: 0,
+		},
+		{
+			name: "energy counters bridge gaps",
+			records: []record{
+				{mystrom.SwitchReport{BootID: "a", EnergySinceBoot: 3.6e6, TimeSinceBoot: 10}, 0},
+				{mystrom.SwitchReport{BootID: "a", EnergySinceBoot: 7.2e6, TimeSinceBoot: 3610}, time.Hour},
+			},
+			want: 1,
+		},
+		{
+			name: "reboot resets counters",
+			records: []record{
+				{mystrom.SwitchReport{BootID: "a", EnergySinceBoot: 3.6e6, TimeSinceBoot: 10}, 0},
+				{mystrom.SwitchReport{BootID: "b", EnergySinceBoot: 1.8e6, TimeSinceBoot: 20}, time.Minute},
+				{mystrom.SwitchReport{BootID: "b", EnergySinceBoot: 3.6e6, TimeSinceBoot: 80}, 2 * time.Minute},
+			},
+			want: 1,
+		},
+		{
+			name: "clock going backwards",
+			records: []record{
+				{mystrom.SwitchReport{Power: 1000}, time.Minute},
+				{mystrom.SwitchReport{Power: 1000}, 0},
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := energy.Meter{Location: time.UTC}
+			for _, r := range tt.records {
+				m.Record("sw", r.report, start.Add(r.offset))
+			}
+
+			if got := m.Totals("sw").Total; !almostEqual(got, tt.want) {
+				t.Errorf("Meter.Totals().Total = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeter_Buckets(t *testing.T) {
+	t.Parallel()
+
+	m := energy.Meter{Location: time.UTC}
+	start := time.Date(2024, 1, 31, 23, 30, 0, 0, time.UTC)
+
+	m.Record("sw", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 1}, start)
+	m.Record("sw", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 3601, EnergySinceBoot: 3.6e6}, start.Add(time.Hour))
+
+	totals := m.Totals("sw")
+	if !almostEqual(totals.Hour(start), 0.5) {
+		t.Errorf("Totals.Hour() = %v, want 0.5", totals.Hour(start))
+	}
+	if !almostEqual(totals.Day(start.Add(time.Hour)), 0.5) {
+		t.Errorf("Totals.Day() = %v, want 0.5", totals.Day(start.Add(time.Hour)))
+	}
+	if !almostEqual(totals.Month(start), 0.5) || !almostEqual(totals.Monthly["2024-02"], 0.5) {
+		t.Errorf("Totals.Monthly = %v, want 0.5 per month", totals.Monthly)
+	}
+	if !almostEqual(totals.Total, 1) {
+		t.Errorf("Totals.Total = %v, want 1", totals.Total)
+	}
+}
+
+func TestMeter_BucketsDST(t *testing.T) {
+	t.Parallel()
+
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// clocks go back from 03:00 CEST to 02:00 CET, the hour 02 occurs twice
+	m := energy.Meter{Location: zurich}
+	start := time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC) // 02:00 CEST
+
+	m.Record("sw", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 1}, start)
+	m.Record("sw", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 7201, EnergySinceBoot: 7.2e6}, start.Add(2*time.Hour))
+
+	totals := m.Totals("sw")
+	if len(totals.Hourly) != 2 {
+		t.Errorf("Totals.Hourly = %v, want two hours", totals.Hourly)
+	}
+	for _, at := range []time.Time{start, start.Add(time.Hour)} {
+		if !almostEqual(totals.Hour(at), 1) {
+			t.Errorf("Totals.Hour(%s) = %v, want 1", at.In(zurich), totals.Hour(at))
+		}
+	}
+
+	// the day is looked up in the location of the meter regardless of the zone of at
+	if !almostEqual(totals.Day(time.Date(2024, 10, 26, 23, 0, 0, 0, time.UTC)), 2) {
+		t.Errorf("Totals.Daily = %v, want 2 on 2024-10-27", totals.Daily)
+	}
+}
+
+func TestMeter_Load(t *testing.T) {
+	t.Parallel()
+
+	store := &energy.JSONFile{Path: filepath.Join(t.TempDir(), "energy.json")}
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	m := energy.Meter{Location: time.UTC, Store: store}
+	m.Record("sw", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 10, EnergySinceBoot: 3.6e6}, start)
+	err := m.Save(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the energy consumed while the meter was not running is accounted
+	// from the counters of the restored report
+	restarted := energy.Meter{Location: time.UTC, Store: store}
+	err = restarted.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.Record("sw", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 7210, EnergySinceBoot: 1.08e7}, start.Add(2*time.Hour))
+
+	if got := restarted.Totals("sw").Total; !almostEqual(got, 2) {
+		t.Errorf("Meter.Totals().Total = %v, want 2", got)
+	}
+}
+
+type reporter struct {
+	mu     sync.Mutex
+	energy float64
+	err    error
+}
+
+func (r *reporter) Report(_ context.Context) (*mystrom.SwitchReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.energy += 3.6e6
+	return &mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 1, EnergySinceBoot: r.energy}, nil
+}
+
+func TestMeter_Run(t *testing.T) {
+	t.Parallel()
+
+	store := &energy.JSONFile{Path: filepath.Join(t.TempDir(), "energy.json")}
+	errReporter := errors.New("unreachable")
+
+	var mu sync.Mutex
+	var errs []string
+
+	m := energy.Meter{
+		Interval: 10 * time.Millisecond,
+		Store:    store,
+		OnError: func(id string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, id)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+
+	err := m.Run(ctx, map[string]energy.Reporter{
+		"ok":     &reporter{},
+		"broken": &reporter{err: errReporter},
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Meter.Run() error = %v", err)
+	}
+
+	got := m.Totals("ok").Total
+	if got < 1 {
+		t.Errorf("Meter.Totals().Total = %v, want at least 1", got)
+	}
+
+	mu.Lock()
+	if len(errs) == 0 || errs[0] != "broken" {
+		t.Errorf("expected errors for broken, got %v", errs)
+	}
+	mu.Unlock()
+
+	restored := energy.Meter{Store: store}
+	err = restored.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Totals("ok").Total != got {
+		t.Errorf("restored total = %v, want %v", restored.Totals("ok").Total, got)
+	}
+}
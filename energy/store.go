@@ -0,0 +1,79 @@
+package energy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the totals of a Meter.
+type Store interface {
+	Load(ctx context.Context) (map[string]Totals, error)
+	Save(ctx context.Context, totals map[string]Totals) error
+}
+
+// JSONFile stores the totals in a JSON file.
+type JSONFile struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Load reads the totals from the file, a missing file results in empty totals.
+func (f *JSONFile) Load(_ context.Context) (map[string]Totals, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	totals := map[string]Totals{}
+
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return totals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &totals)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, t := range totals {
+		totals[id] = t.clone()
+	}
+	return totals, nil
+}
+
+// Save atomically replaces the file with the given totals.
+func (f *JSONFile) Save(_ context.Context, totals map[string]Totals) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(totals, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
@@ -0,0 +1,52 @@
+package energy_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/energy"
+)
+
+func TestJSONFile(t *testing.T) {
+	t.Parallel()
+
+	store := &energy.JSONFile{Path: filepath.Join(t.TempDir(), "energy.json")}
+
+	totals, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 0 {
+		t.Errorf("JSONFile.Load() = %v, want empty totals", totals)
+	}
+
+	want := map[string]energy.Totals{
+		"5CCF7F123456": {
+			Total:   1.5,
+			Hourly:  map[string]float64{"2024-03-01T10Z": 1.5},
+			Daily:   map[string]float64{"2024-03-01": 1.5},
+			Monthly: map[string]float64{"2024-03": 1.5},
+			Last: &energy.Sample{
+				At:     time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+				Report: mystrom.SwitchReport{BootID: "a", EnergySinceBoot: 5.4e6, TimeSinceBoot: 1800},
+			},
+		},
+	}
+
+	err = store.Save(context.Background(), want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSONFile.Load() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package mystrom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrPowerCycleRelayOff is returned if a power cycle was rejected because the relay is off.
+	ErrPowerCycleRelayOff = errors.New("power cycle rejected, relay is off")
+	// ErrPowerCycleOutOfRange is returned if the wait time of a power cycle exceeds one hour.
+	ErrPowerCycleOutOfRange = errors.New("power cycle wait out of range")
+	// ErrTimerOutOfRange is returned if the duration of a timer exceeds 24 hours or was rejected by the Switch.
+	ErrTimerOutOfRange = errors.New("timer out of range")
+)
+
+// APIError represents a non success status code returned by a device.
+// It matches ErrStatus and, depending on the endpoint, one of the more
+// specific errors like ErrPowerCycleRelayOff using errors.Is.
+type APIError struct {
+	StatusCode int
+	Body       string   // trimmed response body
+	Endpoint   string   // path of the request, e.g. /power_cycle
+	DeviceURL  *url.URL // base URL of the device
+}
+
+func newAPIError(req *http.Request, statusCode int, body []byte) *APIError {
+	e := APIError{
+		StatusCode: statusCode,
+		Body:       string(body),
+	}
+
+	if req != nil && req.URL != nil {
+		e.Endpoint = req.URL.Path
+		e.DeviceURL = &url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host}
+	}
+
+	return &e
+}
+
+func (e *APIError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("%s: %d, %s '%s'", http.StatusText(e.StatusCode), e.StatusCode, ErrStatus, e.Body)
+	}
+	return fmt.Sprintf("%s: %d, %s", http.StatusText(e.StatusCode), e.StatusCode, ErrStatus)
+}
+
+func (e *APIError) Unwrap() error {
+	return ErrStatus
+}
+
+// Is reports whether the error corresponds to one of the known device replies.
+// The devices do not describe the reason of a 400 reply, a power cycle is
+// only matched as rejected because of the relay since Switch.PowerCycle
+// validates the wait time before sending.
+func (e *APIError) Is(target error) bool {
+	if e.StatusCode != http.StatusBadRequest {
+		return false
+	}
+
+	switch target {
+	case ErrPowerCycleRelayOff:
+		return strings.HasSuffix(e.Endpoint, "/power_cycle")
+	case ErrTimerOutOfRange:
+		return strings.HasSuffix(e.Endpoint, "/timer")
+	default:
+		return false
+	}
+}
+
+// Temporary reports whether the request may succeed if it is sent again.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,109 @@
+package mystrom_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		call          func(mystrom.Switch, context.Context) error
+		wantEndpoint  string
+		wantIs        []error
+		wantIsNot     []error
+		wantTemporary bool
+	}{
+		{
+			name:       "power cycle relay off",
+			statusCode: http.StatusBadRequest,
+			body:       "relay is off\n",
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.PowerCycle(ctx, time.Minute)
+			},
+			wantEndpoint: "/power_cycle",
+			wantIs:       []error{mystrom.ErrStatus, mystrom.ErrPowerCycleRelayOff},
+			wantIsNot:    []error{mystrom.ErrTimerOutOfRange},
+		},
+		{
+			name:       "timer rejected",
+			statusCode: http.StatusBadRequest,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.Timer(ctx, mystrom.SwitchTimerModeOn, 12*time.Hour)
+			},
+			wantEndpoint: "/timer",
+			wantIs:       []error{mystrom.ErrStatus, mystrom.ErrTimerOutOfRange},
+			wantIsNot:    []error{mystrom.ErrPowerCycleRelayOff},
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusInternalServerError,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.PowerCycle(ctx, time.Minute)
+			},
+			wantEndpoint:  "/power_cycle",
+			wantIs:        []error{mystrom.ErrStatus},
+			wantIsNot:     []error{mystrom.ErrPowerCycleRelayOff},
+			wantTemporary: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.call(*mystrom.NewClient().NewSwitch(baseURL), context.Background())
+
+			var apiErr *mystrom.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected APIError, got %v", err)
+			}
+
+			if apiErr.StatusCode != tt.statusCode {
+				t.Errorf("APIError.StatusCode = %d, want %d", apiErr.StatusCode, tt.statusCode)
+			}
+			if apiErr.Endpoint != tt.wantEndpoint {
+				t.Errorf("APIError.Endpoint = %s, want %s", apiErr.Endpoint, tt.wantEndpoint)
+			}
+			if apiErr.DeviceURL.String() != ts.URL {
+				t.Errorf("APIError.DeviceURL = %s, want %s", apiErr.DeviceURL, ts.URL)
+			}
+			if apiErr.Body != "relay is off" && tt.body != "" {
+				t.Errorf("APIError.Body = %q, want trimmed body", apiErr.Body)
+			}
+			if apiErr.Temporary() != tt.wantTemporary {
+				t.Errorf("APIError.Temporary() = %v, want %v", apiErr.Temporary(), tt.wantTemporary)
+			}
+
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("expected errors.Is(%v, %v)", err, target)
+				}
+			}
+			for _, target := range tt.wantIsNot {
+				if errors.Is(err, target) {
+					t.Errorf("expected !errors.Is(%v, %v)", err, target)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package mystrom
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FirmwareVersion is a dot separated firmware version, e.g. 3.82.60.
+type FirmwareVersion string
+
+// Compare returns -1 if v is older than o, 0 if they are equal and +1 if v
+// is newer than o. Segments are compared numerically, missing segments
+// count as zero.
+func (v FirmwareVersion) Compare(o FirmwareVersion) int {
+	a := strings.Split(string(v), ".")
+	b := strings.Split(string(o), ".")
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := segment(a, i), segment(b, i)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func segment(s []string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(s[i]))
+	return n
+}
+
+// Firmware returns the firmware version the device runs.
+func (c *Client) Firmware(ctx context.Context, baseURL *url.URL) (FirmwareVersion, error) {
+	info, err := c.Info(ctx, baseURL)
+	if err != nil {
+		return "", err
+	}
+	return FirmwareVersion(info.Version), nil
+}
+
+// FirmwareProgress is called while a firmware image is uploaded with the
+// number of bytes sent so far and the total size of the image.
+type FirmwareProgress func(sent, total int64)
+
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress FirmwareProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
+
+// UpdateFirmware uploads a firmware image of the given size to the device,
+// which installs it and restarts. The image is streamed, progress may be
+// nil. The upload is not retried once it was sent.
+func (c *Client) UpdateFirmware(
+	ctx context.Context,
+	baseURL *url.URL,
+	name string,
+	image io.Reader,
+	size int64,
+	progress FirmwareProgress,
+) error {
+	// the multipart framing is written up front to send a content length,
+	// which the devices require instead of a chunked body
+	head := &bytes.Buffer{}
+	mw := multipart.NewWriter(head)
+	_, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	boundary := head.Len()
+	err = mw.Close()
+	if err != nil {
+		return err
+	}
+	tail := bytes.NewReader(head.Bytes()[boundary:])
+	head.Truncate(boundary)
+
+	if progress != nil {
+		image = &progressReader{r: image, total: size, progress: progress}
+	}
+
+	req, err := c.newRequest(ctx, baseURL, http.MethodPost, "load", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Body = io.NopCloser(io.MultiReader(head, io.LimitReader(image, size), tail))
+	req.ContentLength = int64(head.Len()) + size + tail.Size()
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return c.doDiscard(nonIdempotent(req))
+}
+
+// UpdateFirmwareFile uploads the firmware image at path to the device, see UpdateFirmware.
+func (c *Client) UpdateFirmwareFile(ctx context.Context, baseURL *url.URL, path string, progress FirmwareProgress) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening firmware: %w", err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading firmware: %w", err)
+	}
+
+	return c.UpdateFirmware(ctx, baseURL, filepath.Base(path), f, stat.Size(), progress)
+}
@@ -0,0 +1,116 @@
+package mystrom_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func TestFirmwareVersion_Compare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		v, o mystrom.FirmwareVersion
+		want int
+	}{
+		{v: "3.82.60", o: "3.82.60", want: 0},
+		{v: "3.82.60", o: "3.82.61", want: -1},
+		{v: "3.100.0", o: "3.82.60", want: 1},
+		{v: "3.82", o: "3.82.0", want: 0},
+		{v: "2.58.0", o: "3", want: -1},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Compare(tt.o); got != tt.want {
+			t.Errorf("FirmwareVersion(%s).Compare(%s) = %d, want %d", tt.v, tt.o, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Firmware(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(mystromtest.NewSwitch(mystromtest.SwitchConfig{Version: "3.82.60"}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := mystrom.NewClient().Firmware(context.Background(), baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "3.82.60" {
+		t.Errorf("Client.Firmware() = %s, want 3.82.60", version)
+	}
+}
+
+func TestClient_UpdateFirmwareFile(t *testing.T) {
+	t.Parallel()
+
+	image := bytes.Repeat([]byte{0xAB}, 100_000)
+	path := filepath.Join(t.TempDir(), "switch.bin")
+	if err := os.WriteFile(path, image, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/load" {
+			t.Errorf("expected /load path, got %s", r.URL.Path)
+		}
+
+		if len(r.TransferEncoding) > 0 || r.ContentLength <= int64(len(image)) {
+			t.Errorf("expected content length, got %d %v", r.ContentLength, r.TransferEncoding)
+		}
+
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		if header.Filename != "switch.bin" {
+			t.Errorf("expected filename switch.bin, got %s", header.Filename)
+		}
+
+		got, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, image) {
+			t.Errorf("expected %d bytes of firmware, got %d", len(image), len(got))
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent, total int64
+	err = mystrom.NewClient().UpdateFirmwareFile(context.Background(), baseURL, path, func(s, t int64) {
+		sent, total = s, t
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != int64(len(image)) || total != int64(len(image)) {
+		t.Errorf("expected progress %d/%d, got %d/%d", len(image), len(image), sent, total)
+	}
+}
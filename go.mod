@@ -1,3 +1,3 @@
 module thde.io/mystrom
 
-go 1.20
+go 1.21
@@ -0,0 +1,133 @@
+package mystrom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Group sends the same command to several Switches concurrently.
+type Group struct {
+	Members []*Switch
+	// Parallelism limits the number of concurrent requests, defaults to
+	// all members at once.
+	Parallelism int
+}
+
+// GroupResult is the outcome of a command for a single member of a Group.
+type GroupResult struct {
+	Switch *Switch
+	Report *SwitchReport // only set by Group.Report if Err is nil
+	Err    error
+}
+
+// GroupError lists the members of a Group for which a command failed. It
+// matches the errors of all failed members using errors.Is and errors.As.
+type GroupError struct {
+	Failed []GroupResult
+	Total  int
+}
+
+func (e *GroupError) Error() string {
+	failed := make([]string, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		u := r.Switch.URL()
+		failed = append(failed, fmt.Sprintf("%s: %s", u.Host, r.Err))
+	}
+	return fmt.Sprintf("%d of %d switches failed: %s", len(e.Failed), e.Total, strings.Join(failed, "; "))
+}
+
+func (e *GroupError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		errs = append(errs, r.Err)
+	}
+	return errs
+}
+
+// each calls fn for every member respecting the parallelism limit. The
+// results are in the order of the members, the error is a *GroupError if
+// fn failed for any member.
+func (g *Group) each(ctx context.Context, fn func(context.Context, *Switch, *GroupResult)) ([]GroupResult, error) {
+	parallelism := g.Parallelism
+	if parallelism <= 0 || parallelism > len(g.Members) {
+		parallelism = len(g.Members)
+	}
+
+	results := make([]GroupResult, len(g.Members))
+	sem := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+
+	for i, s := range g.Members {
+		results[i].Switch = s
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *Switch, result *GroupResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fn(ctx, s, result)
+		}(s, &results[i])
+	}
+	wg.Wait()
+
+	groupErr := &GroupError{Total: len(results)}
+	for _, r := range results {
+		if r.Err != nil {
+			groupErr.Failed = append(groupErr.Failed, r)
+		}
+	}
+	if len(groupErr.Failed) > 0 {
+		return results, groupErr
+	}
+	return results, nil
+}
+
+// On turns the power of all Switches on.
+func (g *Group) On(ctx context.Context) ([]GroupResult, error) {
+	return g.each(ctx, func(ctx context.Context, s *Switch, r *GroupResult) {
+		r.Err = s.On(ctx)
+	})
+}
+
+// Off turns the power of all Switches off.
+func (g *Group) Off(ctx context.Context) ([]GroupResult, error) {
+	return g.each(ctx, func(ctx context.Context, s *Switch, r *GroupResult) {
+		r.Err = s.Off(ctx)
+	})
+}
+
+// Toggle toggles the power state of all Switches. It is not idempotent.
+func (g *Group) Toggle(ctx context.Context) ([]GroupResult, error) {
+	return g.each(ctx, func(ctx context.Context, s *Switch, r *GroupResult) {
+		r.Err = s.Toggle(ctx)
+	})
+}
+
+// Report returns the reports of all Switches.
+func (g *Group) Report(ctx context.Context) ([]GroupResult, error) {
+	return g.each(ctx, func(ctx context.Context, s *Switch, r *GroupResult) {
+		report, err := s.Report(ctx)
+		if err != nil {
+			r.Err = err
+			return
+		}
+		r.Report = report
+	})
+}
+
+// PowerCycle power cycles all Switches, see Switch.PowerCycle.
+func (g *Group) PowerCycle(ctx context.Context, wait time.Duration) ([]GroupResult, error) {
+	return g.each(ctx, func(ctx context.Context, s *Switch, r *GroupResult) {
+		r.Err = s.PowerCycle(ctx, wait)
+	})
+}
@@ -0,0 +1,117 @@
+package mystrom_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func newGroupMember(t *testing.T, h http.Handler) *mystrom.Switch {
+	t.Helper()
+
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mystrom.NewSwitch(baseURL)
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	kitchen := mystromtest.NewSwitch(mystromtest.SwitchConfig{Load: 20})
+	lamp := mystromtest.NewSwitch(mystromtest.SwitchConfig{Load: 40})
+	broken := newGroupMember(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	g := mystrom.Group{
+		Members: []*mystrom.Switch{newGroupMember(t, kitchen), broken, newGroupMember(t, lamp)},
+	}
+
+	results, err := g.On(ctx)
+	if len(results) != 3 {
+		t.Fatalf("Group.On() returned %d results, want 3", len(results))
+	}
+	if results[0].Err != nil || results[1].Err == nil || results[2].Err != nil {
+		t.Errorf("Group.On() results = %+v, want only the second to fail", results)
+	}
+	if !kitchen.Relay() || !lamp.Relay() {
+		t.Error("expected relays to be on")
+	}
+
+	var groupErr *mystrom.GroupError
+	if !errors.As(err, &groupErr) || len(groupErr.Failed) != 1 || groupErr.Failed[0].Switch != broken {
+		t.Fatalf("Group.On() error = %v, want group error for the broken switch", err)
+	}
+	if u := broken.URL(); !strings.Contains(err.Error(), "1 of 3 switches failed: "+u.Host) {
+		t.Errorf("Group.On() error = %q", err)
+	}
+	if !errors.Is(err, mystrom.ErrStatus) {
+		t.Errorf("Group.On() error = %v, want %v", err, mystrom.ErrStatus)
+	}
+
+	results, _ = g.Report(ctx)
+	if results[1].Err == nil || results[1].Report != nil {
+		t.Errorf("Group.Report() result of the broken switch = %+v, want only an error", results[1])
+	}
+
+	g.Members = []*mystrom.Switch{g.Members[0], g.Members[2]}
+	results, err = g.Report(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Report.Power != 20 || results[1].Report.Power != 40 {
+		t.Errorf("Group.Report() = %v, %v", results[0].Report, results[1].Report)
+	}
+
+	if _, err := g.Off(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if kitchen.Relay() || lamp.Relay() {
+		t.Error("expected relays to be off")
+	}
+}
+
+func TestGroup_Parallelism(t *testing.T) {
+	t.Parallel()
+
+	var inflight, maxInflight int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxInflight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInflight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	g := mystrom.Group{Parallelism: 2}
+	for i := 0; i < 6; i++ {
+		g.Members = append(g.Members, newGroupMember(t, h))
+	}
+
+	if _, err := g.Toggle(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if m := atomic.LoadInt32(&maxInflight); m > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", m)
+	}
+}
@@ -0,0 +1,129 @@
+package mystrom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DeviceInfo represents the general information every myStrom device provides.
+type DeviceInfo struct {
+	Version   string           `json:"version"` // firmware version
+	MAC       net.HardwareAddr `json:"-"`
+	Type      DeviceType       `json:"type"`
+	SSID      string           `json:"ssid"`
+	IP        net.IP           `json:"ip"`
+	Mask      net.IP           `json:"mask"`
+	Gateway   net.IP           `json:"gw"`
+	DNS       net.IP           `json:"dns"`
+	Static    bool             `json:"static"`    // true if the network is configured statically
+	Connected bool             `json:"connected"` // true if connected to the myStrom cloud
+}
+
+func (i *DeviceInfo) UnmarshalJSON(data []byte) error {
+	type info DeviceInfo
+	aux := struct {
+		*info
+		MAC string `json:"mac"`
+	}{info: (*info)(i)}
+
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+
+	i.MAC, err = parseMAC(aux.MAC)
+	return err
+}
+
+// parseMAC parses MAC addresses with or without separators as returned by the devices.
+func parseMAC(s string) (net.HardwareAddr, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if len(s) == 12 {
+		var b strings.Builder
+		for i := 0; i < len(s); i += 2 {
+			if i > 0 {
+				b.WriteByte(':')
+			}
+			b.WriteString(s[i : i+2])
+		}
+		s = b.String()
+	}
+
+	mac, err := net.ParseMAC(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mac address '%s': %w", s, err)
+	}
+	return mac, nil
+}
+
+// UnmarshalJSON decodes a device type provided as number or numeric string.
+func (d *DeviceType) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return fmt.Errorf("invalid device type %s: %w", data, err)
+	}
+
+	*d = DeviceType(v)
+	return nil
+}
+
+// Info returns the general information of the device at baseURL.
+// It can be used with any device type.
+func (c *Client) Info(ctx context.Context, baseURL *url.URL) (*DeviceInfo, error) {
+	info := DeviceInfo{}
+
+	req, err := c.newRequest(ctx, baseURL, http.MethodGet, "api/v1/info", nil, nil)
+	if err != nil {
+		return &info, err
+	}
+
+	_, err = c.doJSON(req, &info)
+	return &info, err
+}
+
+// Info returns the general information of the Switch.
+func (s Switch) Info(ctx context.Context) (*DeviceInfo, error) {
+	info := DeviceInfo{}
+
+	req, err := s.newRequest(ctx, http.MethodGet, "api/v1/info", nil, nil)
+	if err != nil {
+		return &info, err
+	}
+
+	err = s.doJSON(req, &info)
+	return &info, err
+}
+
+// Info returns the general information of the Bulb.
+func (b *Bulb) Info(ctx context.Context) (*DeviceInfo, error) {
+	return b.device.client.Info(ctx, b.device.baseURL)
+}
+
+// Info returns the general information of the LED Strip.
+func (l *LEDStrip) Info(ctx context.Context) (*DeviceInfo, error) {
+	return l.device.client.Info(ctx, l.device.baseURL)
+}
+
+// Info returns the general information of the Button.
+func (b *Button) Info(ctx context.Context) (*DeviceInfo, error) {
+	return b.device.client.Info(ctx, b.device.baseURL)
+}
+
+// Info returns the general information of the Motion Sensor.
+func (m MotionSensor) Info(ctx context.Context) (*DeviceInfo, error) {
+	return m.client.Info(ctx, m.baseURL)
+}
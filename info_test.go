@@ -0,0 +1,123 @@
+package mystrom_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func TestClient_Info(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		body       []byte
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *mystrom.DeviceInfo
+		wantErr bool
+	}{
+		{
+			name: "switch",
+			args: args{
+				body:       []byte(`{"version": "3.82.60", "mac": "5CCF7F123456", "type": 107, "ssid": "home", "ip": "192.168.1.20", "mask": "255.255.255.0", "gw": "192.168.1.1", "dns": "192.168.1.1", "static": false, "connected": true}`),
+				statusCode: http.StatusOK,
+			},
+			want: &mystrom.DeviceInfo{
+				Version:   "3.82.60",
+				MAC:       net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x56},
+				Type:      mystrom.DeviceTypeSwitchEU,
+				SSID:      "home",
+				IP:        net.ParseIP("192.168.1.20"),
+				Mask:      net.ParseIP("255.255.255.0"),
+				Gateway:   net.ParseIP("192.168.1.1"),
+				DNS:       net.ParseIP("192.168.1.1"),
+				Connected: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "string type and separated mac",
+			args: args{
+				body:       []byte(`{"version": "2.74.10", "mac": "5c:cf:7f:12:34:57", "type": "118", "static": true}`),
+				statusCode: http.StatusOK,
+			},
+			want: &mystrom.DeviceInfo{
+				Version: "2.74.10",
+				MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x57},
+				Type:    mystrom.DeviceTypeButtonPlus2ndGeneration,
+				Static:  true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid mac",
+			args: args{
+				body:       []byte(`{"mac": "invalid"}`),
+				statusCode: http.StatusOK,
+			},
+			wantErr: true,
+		},
+		{
+			name: "error",
+			args: args{
+				statusCode: http.StatusInternalServerError,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/info" {
+					t.Errorf("expected /api/v1/info path, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.args.statusCode)
+				_, _ = w.Write(tt.args.body)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+
+			info, err := client.Info(context.Background(), baseURL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.Info() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(info, tt.want) {
+				t.Errorf("Client.Info() = %+v, want %+v", info, tt.want)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			info, err = client.NewSwitch(baseURL).Info(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(info, tt.want) {
+				t.Errorf("Switch.Info() = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package mystrom
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// LEDStrip holds all info and logic to talk your myStrom LED Strip device.
+// The local API of the LED Strip only sets a single color for the whole
+// strip, effects and per-segment control are only available in the app.
+type LEDStrip struct {
+	device deviceAPI
+}
+
+// NewLEDStrip creates a new LEDStrip instance.
+func (c *Client) NewLEDStrip(baseURL *url.URL) *LEDStrip {
+	return &LEDStrip{
+		device: deviceAPI{
+			baseURL: baseURL,
+			client:  c,
+		},
+	}
+}
+
+// NewLEDStrip creates a new LEDStrip instance with a default client.
+func NewLEDStrip(baseURL *url.URL) *LEDStrip {
+	return NewClient().NewLEDStrip(baseURL)
+}
+
+// LEDStripState represents the state of a LED Strip.
+type LEDStripState struct {
+	MAC             string    `json:"-"`
+	Type            string    `json:"type"`
+	Reachable       bool      `json:"reachable"`
+	MeshRoot        bool      `json:"meshroot"`
+	On              bool      `json:"on"`
+	Color           string    `json:"color"` // format depends on Mode
+	Mode            ColorMode `json:"mode"`
+	Ramp            int       `json:"ramp"`  // transition time in milliseconds
+	Power           float64   `json:"power"` // current power consumption in watts
+	FirmwareVersion string    `json:"fw_version"`
+}
+
+// HSV returns the color of the LED Strip if it is in HSV mode.
+func (s LEDStripState) HSV() (HSV, error) {
+	if s.Mode != ColorModeHSV {
+		return HSV{}, fmt.Errorf("led strip is in %s mode", s.Mode)
+	}
+	return parseHSV(s.Color)
+}
+
+// RGBW returns the color of the LED Strip if it is in RGB mode.
+func (s LEDStripState) RGBW() (RGBW, error) {
+	if s.Mode != ColorModeRGB {
+		return RGBW{}, fmt.Errorf("led strip is in %s mode", s.Mode)
+	}
+	return parseRGBW(s.Color)
+}
+
+// RampTime returns the configured transition time.
+func (s LEDStripState) RampTime() time.Duration {
+	return time.Duration(s.Ramp) * time.Millisecond
+}
+
+// State returns the current state of the LED Strip.
+func (l *LEDStrip) State(ctx context.Context) (*LEDStripState, error) {
+	state := LEDStripState{}
+
+	mac, err := l.device.state(ctx, &state)
+	state.MAC = mac
+	return &state, err
+}
+
+// On turns the LED Strip on.
+func (l *LEDStrip) On(ctx context.Context) error {
+	return l.device.set(ctx, url.Values{"action": []string{"on"}})
+}
+
+// Off turns the LED Strip off.
+func (l *LEDStrip) Off(ctx context.Context) error {
+	return l.device.set(ctx, url.Values{"action": []string{"off"}})
+}
+
+// Toggle toggles the power state of the LED Strip.
+func (l *LEDStrip) Toggle(ctx context.Context) error {
+	return l.device.set(ctx, url.Values{"action": []string{"toggle"}})
+}
+
+// SetHSV sets the color of the LED Strip in the HSV color space.
+func (l *LEDStrip) SetHSV(ctx context.Context, color HSV) error {
+	return l.device.set(ctx, url.Values{
+		"mode":  []string{string(ColorModeHSV)},
+		"color": []string{color.String()},
+	})
+}
+
+// SetRGBW sets the color of the LED Strip using the WWRRGGBB hex notation.
+func (l *LEDStrip) SetRGBW(ctx context.Context, color RGBW) error {
+	return l.device.set(ctx, url.Values{
+		"mode":  []string{string(ColorModeRGB)},
+		"color": []string{color.String()},
+	})
+}
+
+// Transition changes the color of the LED Strip in the HSV color space over the given ramp time.
+func (l *LEDStrip) Transition(ctx context.Context, color HSV, ramp time.Duration) error {
+	return l.device.set(ctx, url.Values{
+		"mode":  []string{string(ColorModeHSV)},
+		"color": []string{color.String()},
+		"ramp":  []string{strconv.Itoa(int(ramp.Milliseconds()))},
+	})
+}
+
+// SetRamp sets the transition time used for color and power changes.
+func (l *LEDStrip) SetRamp(ctx context.Context, ramp time.Duration) error {
+	return l.device.set(ctx, url.Values{"ramp": []string{strconv.Itoa(int(ramp.Milliseconds()))}})
+}
+
+func (l *LEDStrip) URL() url.URL {
+	return *l.device.baseURL
+}
@@ -0,0 +1,189 @@
+package mystrom_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+const ledStripState = `{"5CCF7F000002": {"type": "strip", "battery": false, "reachable": true, "meshroot": true, "on": false, "color": "00FF8000", "mode": "rgb", "ramp": 500, "power": 0.6, "fw_version": "3.12.0"}}`
+
+func TestLEDStrip_State(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		body       []byte
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *mystrom.LEDStripState
+		wantErr bool
+	}{
+		{
+			name: "success",
+			args: args{
+				body:       []byte(ledStripState),
+				statusCode: http.StatusOK,
+			},
+			want: &mystrom.LEDStripState{
+				MAC:             "5CCF7F000002",
+				Type:            "strip",
+				Reachable:       true,
+				MeshRoot:        true,
+				Color:           "00FF8000",
+				Mode:            mystrom.ColorModeRGB,
+				Ramp:            500,
+				Power:           0.6,
+				FirmwareVersion: "3.12.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "error",
+			args: args{
+				statusCode: http.StatusInternalServerError,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/device" {
+					t.Errorf("expected /api/v1/device path, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.args.statusCode)
+				_, _ = w.Write(tt.args.body)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			l := client.NewLEDStrip(baseURL)
+
+			state, err := l.State(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LEDStrip.State() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(state, tt.want) {
+				t.Errorf("LEDStrip.State() = %v, want %v", state, tt.want)
+			}
+
+			color, err := state.RGBW()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (mystrom.RGBW{R: 0xFF, G: 0x80}); color != want {
+				t.Errorf("LEDStripState.RGBW() = %v, want %v", color, want)
+			}
+		})
+	}
+}
+
+func TestLEDStrip_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		call func(*mystrom.LEDStrip, context.Context) error
+		want url.Values
+	}{
+		{
+			name: "on",
+			call: (*mystrom.LEDStrip).On,
+			want: url.Values{"action": []string{"on"}},
+		},
+		{
+			name: "off",
+			call: (*mystrom.LEDStrip).Off,
+			want: url.Values{"action": []string{"off"}},
+		},
+		{
+			name: "toggle",
+			call: (*mystrom.LEDStrip).Toggle,
+			want: url.Values{"action": []string{"toggle"}},
+		},
+		{
+			name: "rgbw",
+			call: func(l *mystrom.LEDStrip, ctx context.Context) error {
+				return l.SetRGBW(ctx, mystrom.RGBW{G: 0xFF, W: 0x80})
+			},
+			want: url.Values{"mode": []string{"rgb"}, "color": []string{"8000FF00"}},
+		},
+		{
+			name: "hsv",
+			call: func(l *mystrom.LEDStrip, ctx context.Context) error {
+				return l.SetHSV(ctx, mystrom.HSV{Hue: 200, Saturation: 50, Value: 75})
+			},
+			want: url.Values{"mode": []string{"hsv"}, "color": []string{"200;50;75"}},
+		},
+		{
+			name: "transition",
+			call: func(l *mystrom.LEDStrip, ctx context.Context) error {
+				return l.Transition(ctx, mystrom.HSV{Hue: 10, Saturation: 20, Value: 30}, 1500*time.Millisecond)
+			},
+			want: url.Values{"mode": []string{"hsv"}, "color": []string{"10;20;30"}, "ramp": []string{"1500"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && r.URL.Path == "/api/v1/device" {
+					_, _ = w.Write([]byte(ledStripState))
+					return
+				}
+
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/device/5CCF7F000002" {
+					t.Errorf("expected /api/v1/device/5CCF7F000002 path, got %s", r.URL.Path)
+				}
+
+				if !reflect.DeepEqual(r.URL.Query(), tt.want) {
+					t.Errorf("expected %v query parameters, got %v", tt.want, r.URL.Query())
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			l := client.NewLEDStrip(baseURL)
+
+			err = tt.call(l, context.Background())
+			if err != nil {
+				t.Errorf("LEDStrip %s error = %v", tt.name, err)
+			}
+		})
+	}
+}
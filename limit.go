@@ -0,0 +1,137 @@
+package mystrom
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithPerHostConcurrency limits the number of concurrent requests to a single
+// device to n. Further requests are queued until a request finished or their
+// context is canceled.
+func WithPerHostConcurrency(n int) Option {
+	return func(c *Client) {
+		if c.limiter == nil {
+			c.limiter = &hostLimiter{}
+		}
+		c.limiter.concurrency = n
+	}
+}
+
+// WithPerHostRate limits the requests to a single device to r per second.
+func WithPerHostRate(r float64) Option {
+	return func(c *Client) {
+		if c.limiter == nil {
+			c.limiter = &hostLimiter{}
+		}
+		c.limiter.rate = r
+	}
+}
+
+// hostLimiter queues requests per device base URL.
+type hostLimiter struct {
+	concurrency int
+	rate        float64
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+type hostLimit struct {
+	sem chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (l *hostLimiter) host(host string) *hostLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.hosts == nil {
+		l.hosts = map[string]*hostLimit{}
+	}
+
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimit{}
+		if l.concurrency > 0 {
+			h.sem = make(chan struct{}, l.concurrency)
+		}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// acquire blocks until a request to host may be sent. The returned release
+// func must be called once the request finished.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	h := l.host(host)
+
+	release := func() {}
+	if h.sem != nil {
+		select {
+		case h.sem <- struct{}{}:
+			release = func() { <-h.sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if l.rate > 0 {
+		h.mu.Lock()
+		now := time.Now()
+		at := h.next
+		if at.Before(now) {
+			at = now
+		}
+		h.next = at.Add(time.Duration(float64(time.Second) / l.rate))
+		h.mu.Unlock()
+
+		if wait := time.Until(at); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// roundTrip sends req once a slot for its host is available. The slot is
+// released when the response body is closed.
+func (l *hostLimiter) roundTrip(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	release, err := l.acquire(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := send(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseBody struct {
+	io.ReadCloser
+
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,118 @@
+package mystrom_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+func TestWithPerHostConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var current, peak int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"power": 1, "relay": true}`))
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := mystrom.NewClient(mystrom.WithPerHostConcurrency(2)).NewSwitch(baseURL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Report(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", got)
+	}
+}
+
+func TestWithPerHostRate(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := mystrom.NewClient(mystrom.WithPerHostRate(50)).NewSwitch(baseURL)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := s.On(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected 4 requests at 50/s to take at least 60ms, took %s", elapsed)
+	}
+}
+
+func TestWithPerHostConcurrency_canceled(t *testing.T) {
+	t.Parallel()
+
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-block
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := mystrom.NewClient(mystrom.WithPerHostConcurrency(1)).NewSwitch(baseURL)
+
+	go func() {
+		_ = s.On(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = s.Off(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Switch.Off() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
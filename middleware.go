@@ -0,0 +1,188 @@
+package mystrom
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// RoundTripFunc sends a single request to a device.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc, e.g. to observe or rewrite requests.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middlewares which are applied to every request sent to
+// a device, including retries. The first middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	rt := RoundTripFunc(c.httpClient.Do)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+
+	return rt(req)
+}
+
+// LoggingMiddleware logs every request with its status and latency to logger.
+// Failed requests are logged with level warn, all others with level debug.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.String()),
+				slog.Duration("latency", time.Since(start)),
+			}
+
+			level := slog.LevelDebug
+			switch {
+			case err != nil:
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("error", err.Error()))
+			case resp.StatusCode >= 400:
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			default:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			}
+
+			logger.LogAttrs(req.Context(), level, "mystrom request", attrs...)
+			return resp, err
+		}
+	}
+}
+
+// RequestStats holds the counters of the requests to a single device.
+type RequestStats struct {
+	Requests     uint64
+	Errors       uint64 // failed requests and status codes >= 400
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// RequestMetrics counts requests, errors and latencies per device host.
+// The zero value is ready to use.
+type RequestMetrics struct {
+	mu    sync.Mutex
+	hosts map[string]*RequestStats
+}
+
+// Middleware returns a Middleware which records to m.
+func (m *RequestMetrics) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			m.record(req.URL.Host, time.Since(start), err != nil || resp.StatusCode >= 400)
+			return resp, err
+		}
+	}
+}
+
+func (m *RequestMetrics) record(host string, latency time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.hosts == nil {
+		m.hosts = map[string]*RequestStats{}
+	}
+
+	stats, ok := m.hosts[host]
+	if !ok {
+		stats = &RequestStats{}
+		m.hosts[host] = stats
+	}
+
+	stats.Requests++
+	if failed {
+		stats.Errors++
+	}
+	stats.TotalLatency += latency
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+}
+
+// Snapshot returns a copy of the counters keyed by device host.
+func (m *RequestMetrics) Snapshot() map[string]RequestStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]RequestStats, len(m.hosts))
+	for host, stats := range m.hosts {
+		snapshot[host] = *stats
+	}
+	return snapshot
+}
+
+// secretFields matches JSON fields holding secrets, like the Wi-Fi password sent by ConnectWiFi.
+var secretFields = regexp.MustCompile(`"(passwd|password)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// redactSecrets replaces the values of secretFields in a JSON body.
+func redactSecrets(body []byte) []byte {
+	return secretFields.ReplaceAll(body, []byte(`"$1"$2"REDACTED"`))
+}
+
+// DumpMiddleware writes every raw request and response to w. The API key
+// in the Token header and passwords in JSON bodies are redacted, other
+// bodies like firmware images are written as they are.
+func DumpMiddleware(w io.Writer) Middleware {
+	var mu sync.Mutex
+
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			dumped := req.Clone(req.Context())
+			if dumped.Header.Get("Token") != "" {
+				dumped.Header.Set("Token", "REDACTED")
+			}
+
+			reqDump, err := httputil.DumpRequestOut(dumped, true)
+			if err != nil {
+				return nil, fmt.Errorf("error dumping request: %w", err)
+			}
+			reqDump = redactSecrets(reqDump)
+
+			// the body was read for the dump, send the restored copy
+			req = req.Clone(req.Context())
+			req.Body = dumped.Body
+
+			resp, err := next(req)
+
+			var respDump []byte
+			if err == nil {
+				respDump, err = httputil.DumpResponse(resp, true)
+				if err != nil {
+					resp.Body.Close()
+					return nil, fmt.Errorf("error dumping response: %w", err)
+				}
+			} else {
+				respDump = []byte(fmt.Sprintf("error: %s\n", err))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			_, werr := fmt.Fprintf(w, "%s\n\n%s\n\n", reqDump, respDump)
+			if werr != nil && err == nil {
+				resp.Body.Close()
+				return nil, fmt.Errorf("error writing dump: %w", werr)
+			}
+
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package mystrom_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Site") != "basel" {
+			t.Errorf("expected rewritten X-Site header, got %q", r.Header.Get("X-Site"))
+		}
+
+		if r.URL.Path == "/toggle" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"power": 10.5, "relay": true}`))
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	trace := func(name string) mystrom.Middleware {
+		return func(next mystrom.RoundTripFunc) mystrom.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(req)
+			}
+		}
+	}
+	rewrite := func(next mystrom.RoundTripFunc) mystrom.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Site", "basel")
+			return next(req)
+		}
+	}
+
+	var logs, dump bytes.Buffer
+	metrics := &mystrom.RequestMetrics{}
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := mystrom.NewClient(
+		mystrom.WithMiddleware(trace("outer"), trace("inner"), rewrite),
+		mystrom.WithMiddleware(
+			mystrom.LoggingMiddleware(logger),
+			metrics.Middleware(),
+			mystrom.DumpMiddleware(&dump),
+		),
+	)
+	s := client.NewSwitch(baseURL)
+
+	report, err := s.Report(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Power != 10.5 {
+		t.Errorf("expected report to be decoded after dumping, got %+v", report)
+	}
+
+	if err := s.Toggle(context.Background()); err == nil {
+		t.Error("expected Switch.Toggle() to fail")
+	}
+
+	if strings.Join(order, ",") != "outer,inner,outer,inner" {
+		t.Errorf("unexpected middleware order %v", order)
+	}
+
+	stats := metrics.Snapshot()[baseURL.Host]
+	if stats.Requests != 2 || stats.Errors != 1 || stats.MaxLatency <= 0 || stats.TotalLatency < stats.MaxLatency {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	for _, want := range []string{"level=DEBUG", "/report", "status=200", "level=WARN", "/toggle", "status=500"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected logs to contain %q, got:\n%s", want, logs.String())
+		}
+	}
+
+	for _, want := range []string{"GET /report HTTP/1.1", "X-Site: basel", `{"power": 10.5, "relay": true}`, "500 Internal Server Error"} {
+		if !strings.Contains(dump.String(), want) {
+			t.Errorf("expected dump to contain %q, got:\n%s", want, dump.String())
+		}
+	}
+}
+
+func TestDumpMiddleware_redacts(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Token") != "secret-key" {
+			t.Errorf("expected the device to receive the api key, got %q", r.Header.Get("Token"))
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), `"passwd":"wifi-secret"`) {
+			t.Errorf("expected the device to receive the password, got %s", body)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dump bytes.Buffer
+	client := mystrom.NewClient(mystrom.WithAPIKey("secret-key"), mystrom.WithMiddleware(mystrom.DumpMiddleware(&dump)))
+
+	err = client.ConnectWiFi(context.Background(), baseURL, mystrom.WiFiConfig{SSID: "Home", Password: "wifi-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"secret-key", "wifi-secret"} {
+		if strings.Contains(dump.String(), secret) {
+			t.Errorf("expected dump to redact %q, got:\n%s", secret, dump.String())
+		}
+	}
+	for _, want := range []string{"Token: REDACTED", `"passwd":"REDACTED"`, `"ssid":"Home"`} {
+		if !strings.Contains(dump.String(), want) {
+			t.Errorf("expected dump to contain %q, got:\n%s", want, dump.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package mystrom
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MotionSensor holds all info and logic to talk your myStrom Motion Sensor (PIR) device.
+type MotionSensor struct {
+	baseURL *url.URL
+	client  *Client
+}
+
+// NewMotionSensor creates a new MotionSensor instance.
+func (c *Client) NewMotionSensor(baseURL *url.URL) *MotionSensor {
+	return &MotionSensor{
+		baseURL: baseURL,
+		client:  c,
+	}
+}
+
+// NewMotionSensor creates a new MotionSensor instance with a default client.
+func NewMotionSensor(baseURL *url.URL) *MotionSensor {
+	return NewClient().NewMotionSensor(baseURL)
+}
+
+func (m MotionSensor) get(ctx context.Context, path string, v interface{}) error {
+	req, err := m.client.newRequest(ctx, m.baseURL, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.client.doJSON(req, v)
+	return err
+}
+
+func (m MotionSensor) post(ctx context.Context, path string, body interface{}) error {
+	req, err := m.client.newRequest(ctx, m.baseURL, http.MethodPost, path, nil, body)
+	if err != nil {
+		return err
+	}
+
+	return m.client.doDiscard(req)
+}
+
+type motionResponse struct {
+	Motion bool `json:"motion"`
+}
+
+// Motion returns true if the Motion Sensor currently detects motion.
+func (m MotionSensor) Motion(ctx context.Context) (bool, error) {
+	motion := motionResponse{}
+	err := m.get(ctx, "api/v1/motion", &motion)
+	return motion.Motion, err
+}
+
+// MotionSensorLight represents the light measurement of the Motion Sensor.
+type MotionSensorLight struct {
+	Intensity float64 `json:"intensity"` // brightness in lux
+	Day       bool    `json:"day"`       // true if the brightness is above the day threshold
+	Raw       struct {
+		Visible int `json:"visible"`
+		IR      int `json:"ir"`
+	} `json:"raw"`
+}
+
+// Light returns the current light level.
+func (m MotionSensor) Light(ctx context.Context) (*MotionSensorLight, error) {
+	light := MotionSensorLight{}
+	err := m.get(ctx, "api/v1/light", &light)
+	return &light, err
+}
+
+// Temperature returns the current temperature in °C.
+func (m MotionSensor) Temperature(ctx context.Context) (*SwitchTemperature, error) {
+	temp := SwitchTemperature{}
+	err := m.get(ctx, "api/v1/temperature", &temp)
+	return &temp, err
+}
+
+// PIRSettings represents the motion detection settings of the Motion Sensor.
+type PIRSettings struct {
+	BackoffTime    int  `json:"backoff_time"`    // seconds without motion until no motion is reported
+	Sensitivity    int  `json:"sensitivity"`     // 0 - 100
+	DayThreshold   int  `json:"day_threshold"`   // brightness in lux above which it is day
+	NightThreshold int  `json:"night_threshold"` // brightness in lux below which it is night
+	LEDEnable      bool `json:"led_enable"`
+}
+
+// Backoff returns the backoff time as a duration.
+func (s PIRSettings) Backoff() time.Duration {
+	return time.Duration(s.BackoffTime) * time.Second
+}
+
+// PIRSettings returns the current motion detection settings.
+func (m MotionSensor) PIRSettings(ctx context.Context) (*PIRSettings, error) {
+	settings := PIRSettings{}
+	err := m.get(ctx, "api/v1/settings/pir", &settings)
+	return &settings, err
+}
+
+// SetPIRSettings changes the motion detection settings.
+func (m MotionSensor) SetPIRSettings(ctx context.Context, settings PIRSettings) error {
+	return m.post(ctx, "api/v1/settings/pir", settings)
+}
+
+// MotionSensorActions holds the action URLs of a Motion Sensor. Actions are
+// of the form get://host/path or post://host/path?query.
+type MotionSensorActions struct {
+	Motion   string `json:"motion,omitempty"`
+	NoMotion string `json:"nomotion,omitempty"`
+	Day      string `json:"day,omitempty"`
+	Night    string `json:"night,omitempty"`
+	Generic  string `json:"generic,omitempty"`
+}
+
+// Actions returns the configured action URLs.
+func (m MotionSensor) Actions(ctx context.Context) (*MotionSensorActions, error) {
+	actions := MotionSensorActions{}
+	err := m.get(ctx, "api/v1/action/pir", &actions)
+	return &actions, err
+}
+
+// SetActions sets all non empty action URLs.
+func (m MotionSensor) SetActions(ctx context.Context, actions MotionSensorActions) error {
+	return m.post(ctx, "api/v1/action/pir", actions)
+}
+
+func (m MotionSensor) URL() url.URL {
+	return *m.baseURL
+}
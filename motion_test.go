@@ -0,0 +1,249 @@
+package mystrom_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func TestMotionSensor_Motion(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		body       []byte
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "motion",
+			args: args{
+				body:       []byte(`{"motion": true}`),
+				statusCode: http.StatusOK,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "no motion",
+			args: args{
+				body:       []byte(`{"motion": false}`),
+				statusCode: http.StatusOK,
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "error",
+			args: args{
+				statusCode: http.StatusInternalServerError,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/motion" {
+					t.Errorf("expected /api/v1/motion path, got %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.args.statusCode)
+				_, _ = w.Write(tt.args.body)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			m := client.NewMotionSensor(baseURL)
+
+			motion, err := m.Motion(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MotionSensor.Motion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if motion != tt.want {
+				t.Errorf("MotionSensor.Motion() = %v, want %v", motion, tt.want)
+			}
+		})
+	}
+}
+
+func TestMotionSensor_Get(t *testing.T) {
+	t.Parallel()
+
+	light := &mystrom.MotionSensorLight{Intensity: 120.5, Day: true}
+	light.Raw.Visible = 1024
+	light.Raw.IR = 300
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		call func(mystrom.MotionSensor, context.Context) (interface{}, error)
+		want interface{}
+	}{
+		{
+			name: "light",
+			path: "/api/v1/light",
+			body: `{"intensity": 120.5, "day": true, "raw": {"visible": 1024, "ir": 300}}`,
+			call: func(m mystrom.MotionSensor, ctx context.Context) (interface{}, error) {
+				return m.Light(ctx)
+			},
+			want: light,
+		},
+		{
+			name: "temperature",
+			path: "/api/v1/temperature",
+			body: `{"measured": 24.5, "compensation": 3, "compensated": 21.5}`,
+			call: func(m mystrom.MotionSensor, ctx context.Context) (interface{}, error) {
+				return m.Temperature(ctx)
+			},
+			want: &mystrom.SwitchTemperature{Measured: 24.5, Compensation: 3, Compensated: 21.5},
+		},
+		{
+			name: "pir settings",
+			path: "/api/v1/settings/pir",
+			body: `{"backoff_time": 60, "sensitivity": 80, "day_threshold": 50, "night_threshold": 10, "led_enable": true}`,
+			call: func(m mystrom.MotionSensor, ctx context.Context) (interface{}, error) {
+				return m.PIRSettings(ctx)
+			},
+			want: &mystrom.PIRSettings{BackoffTime: 60, Sensitivity: 80, DayThreshold: 50, NightThreshold: 10, LEDEnable: true},
+		},
+		{
+			name: "actions",
+			path: "/api/v1/action/pir",
+			body: `{"motion": "get://192.168.1.10/relay?state=1", "nomotion": "get://192.168.1.10/relay?state=0", "day": "", "night": ""}`,
+			call: func(m mystrom.MotionSensor, ctx context.Context) (interface{}, error) {
+				return m.Actions(ctx)
+			},
+			want: &mystrom.MotionSensorActions{Motion: "get://192.168.1.10/relay?state=1", NoMotion: "get://192.168.1.10/relay?state=0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET method, got %s", r.Method)
+				}
+
+				if r.URL.Path != tt.path {
+					t.Errorf("expected %s path, got %s", tt.path, r.URL.Path)
+				}
+
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			m := client.NewMotionSensor(baseURL)
+
+			got, err := tt.call(*m, context.Background())
+			if err != nil {
+				t.Fatalf("MotionSensor %s error = %v", tt.name, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MotionSensor %s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMotionSensor_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		call func(mystrom.MotionSensor, context.Context) error
+		want map[string]interface{}
+	}{
+		{
+			name: "pir settings",
+			path: "/api/v1/settings/pir",
+			call: func(m mystrom.MotionSensor, ctx context.Context) error {
+				return m.SetPIRSettings(ctx, mystrom.PIRSettings{BackoffTime: 30, Sensitivity: 50, DayThreshold: 40, NightThreshold: 5})
+			},
+			want: map[string]interface{}{
+				"backoff_time":    float64(30),
+				"sensitivity":     float64(50),
+				"day_threshold":   float64(40),
+				"night_threshold": float64(5),
+				"led_enable":      false,
+			},
+		},
+		{
+			name: "actions",
+			path: "/api/v1/action/pir",
+			call: func(m mystrom.MotionSensor, ctx context.Context) error {
+				return m.SetActions(ctx, mystrom.MotionSensorActions{Night: "get://192.168.1.10/relay?state=1"})
+			},
+			want: map[string]interface{}{
+				"night": "get://192.168.1.10/relay?state=1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST method, got %s", r.Method)
+				}
+
+				if r.URL.Path != tt.path {
+					t.Errorf("expected %s path, got %s", tt.path, r.URL.Path)
+				}
+
+				body := map[string]interface{}{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Error(err)
+				}
+
+				if !reflect.DeepEqual(body, tt.want) {
+					t.Errorf("expected body %v, got %v", tt.want, body)
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient()
+			m := client.NewMotionSensor(baseURL)
+
+			err = tt.call(*m, context.Background())
+			if err != nil {
+				t.Errorf("MotionSensor %s error = %v", tt.name, err)
+			}
+		})
+	}
+}
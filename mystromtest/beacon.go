@@ -0,0 +1,108 @@
+package mystromtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// Beacons sends discovery beacons on behalf of simulated devices.
+type Beacons struct {
+	// Address the beacons are sent to, defaults to 255.255.255.255:7979.
+	Address string
+	// Network defaults to "udp".
+	Network string
+	// Interval between beacons sent by Run, defaults to mystrom.BeaconInterval.
+	Interval time.Duration
+	// Devices to send beacons for, their Address is ignored.
+	Devices []mystrom.Device
+
+	Dialer net.Dialer
+}
+
+func (b *Beacons) dial(ctx context.Context) (net.Conn, error) {
+	address := b.Address
+	if address == "" {
+		address = "255.255.255.255:7979"
+	}
+	network := b.Network
+	if network == "" {
+		network = "udp"
+	}
+
+	conn, err := b.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("dial error for address %s, network %s: %w", address, network, err)
+	}
+
+	return conn, nil
+}
+
+// Send sends a single beacon for every device.
+func (b *Beacons) Send(ctx context.Context) error {
+	conn, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return b.send(conn)
+}
+
+// send writes a beacon for every device. A refused beacon is not an error,
+// a connected UDP socket reports a missing listener of a previous beacon
+// on the next write, e.g. if the discovery was not started yet.
+func (b *Beacons) send(conn net.Conn) error {
+	for _, d := range b.Devices {
+		payload, err := d.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("error encoding beacon of %s: %w", d.MAC, err)
+		}
+
+		_, err = conn.Write(payload)
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error sending beacon of %s: %w", d.MAC, err)
+		}
+	}
+
+	return nil
+}
+
+// Run sends beacons for every device immediately and then once per
+// interval until ctx is canceled.
+func (b *Beacons) Run(ctx context.Context) error {
+	conn, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	interval := b.Interval
+	if interval <= 0 {
+		interval = mystrom.BeaconInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := b.send(conn)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package mystromtest_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func TestBeacons(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := pc.LocalAddr().String()
+	pc.Close()
+
+	want := mystrom.Device{
+		MAC:       net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x01},
+		Type:      mystrom.DeviceTypeSwitchEU,
+		Cloud:     true,
+		MeshChild: true,
+	}
+
+	beacons := mystromtest.Beacons{
+		Address:  address,
+		Interval: 10 * time.Millisecond,
+		Devices:  []mystrom.Device{want},
+	}
+
+	// the listener may not be ready yet, keep sending until it received a beacon
+	go func() {
+		_ = beacons.Run(ctx)
+	}()
+
+	d := mystrom.Discover{Address: address}
+	got, err := d.Device(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got.Address = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover.Device() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBeacons_errors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := pc.LocalAddr().String()
+	pc.Close()
+
+	// nobody listens, the refused beacons must not stop sending
+	beacons := mystromtest.Beacons{
+		Address:  address,
+		Interval: 5 * time.Millisecond,
+		Devices:  []mystrom.Device{{MAC: net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x01}}},
+	}
+	if err := beacons.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Beacons.Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	beacons.Devices = []mystrom.Device{{MAC: net.HardwareAddr{0x01}}}
+	if err := beacons.Send(context.Background()); err == nil {
+		t.Error("Beacons.Send() expected error for invalid MAC")
+	}
+}
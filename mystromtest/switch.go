@@ -0,0 +1,415 @@
+// Package mystromtest provides simulated myStrom devices for testing.
+package mystromtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+// SwitchConfig configures a simulated Switch.
+type SwitchConfig struct {
+	MAC         net.HardwareAddr   // defaults to 5c:cf:7f:00:00:01
+	Type        mystrom.DeviceType // defaults to mystrom.DeviceTypeSwitchEU
+	Version     string             // firmware version, defaults to 3.82.60
+	Load        float64            // power in watts consumed while the relay is on
+	Temperature float64            // measured temperature in °C, defaults to 25
+	Relay       bool               // initial relay state
+	BootTime    time.Duration      // time the Switch is unavailable after a reboot, defaults to 3s
+}
+
+// Switch simulates the HTTP API of a myStrom Switch. It implements
+// http.Handler and can be served with httptest.NewServer.
+type Switch struct {
+	mac      net.HardwareAddr
+	typ      mystrom.DeviceType
+	version  string
+	bootTime time.Duration
+
+	mu          sync.Mutex
+	bootID      string
+	boot        time.Time
+	booting     bool
+	relay       bool
+	load        float64
+	temperature float64
+	energy      float64 // watt seconds since boot
+	updated     time.Time
+	timer       *time.Timer
+	settings    mystrom.SwitchSettings
+
+	mux *http.ServeMux
+}
+
+// NewSwitch creates a simulated Switch.
+func NewSwitch(cfg SwitchConfig) *Switch {
+	now := time.Now()
+
+	s := &Switch{
+		mac:         cfg.MAC,
+		typ:         cfg.Type,
+		version:     cfg.Version,
+		bootTime:    cfg.BootTime,
+		bootID:      newBootID(),
+		boot:        now,
+		relay:       cfg.Relay,
+		load:        cfg.Load,
+		temperature: cfg.Temperature,
+		updated:     now,
+		settings:    mystrom.SwitchSettings{LED: true},
+		mux:         http.NewServeMux(),
+	}
+
+	if s.mac == nil {
+		s.mac = net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x01}
+	}
+	if s.typ == 0 {
+		s.typ = mystrom.DeviceTypeSwitchEU
+	}
+	if s.version == "" {
+		s.version = "3.82.60"
+	}
+	if s.temperature == 0 {
+		s.temperature = 25
+	}
+	if s.bootTime <= 0 {
+		s.bootTime = 3 * time.Second
+	}
+
+	s.mux.HandleFunc("/toggle", s.handleToggle)
+	s.mux.HandleFunc("/relay", s.handleRelay)
+	s.mux.HandleFunc("/report", s.handleReport)
+	s.mux.HandleFunc("/api/v1/report", s.handleReport)
+	s.mux.HandleFunc("/api/v1/temperature", s.handleTemperature)
+	s.mux.HandleFunc("/power_cycle", s.handlePowerCycle)
+	s.mux.HandleFunc("/timer", s.handleTimer)
+	s.mux.HandleFunc("/api/v1/info", s.handleInfo)
+	s.mux.HandleFunc("/api/v1/settings", s.handleSettings)
+	s.mux.HandleFunc("/api/v1/reboot", s.handleReboot)
+	s.mux.HandleFunc("/api/v1/reset", s.handleReset)
+
+	return s
+}
+
+func newBootID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+func (s *Switch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	booting := s.booting
+	s.mu.Unlock()
+
+	if booting {
+		http.Error(w, "booting", http.StatusServiceUnavailable)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// Reboot restarts the Switch, it does not respond for the configured boot
+// time. Afterwards the relay is restored if enabled in the settings and
+// the energy since boot starts over.
+func (s *Switch) Reboot() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reboot()
+}
+
+// reboot restarts the Switch, s.mu must be held.
+func (s *Switch) reboot() {
+	if s.booting {
+		return
+	}
+
+	s.cancel()
+	s.setRelay(s.relay && s.settings.Restore)
+	s.booting = true
+
+	time.AfterFunc(s.bootTime, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		now := time.Now()
+		s.booting = false
+		s.bootID = newBootID()
+		s.boot = now
+		s.energy = 0
+		s.updated = now
+	})
+}
+
+// Relay returns the current relay state.
+func (s *Switch) Relay() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.relay
+}
+
+// Settings returns the current settings.
+func (s *Switch) Settings() mystrom.SwitchSettings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.settings
+}
+
+// SetLoad changes the power in watts consumed while the relay is on.
+func (s *Switch) SetLoad(load float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.account(time.Now())
+	s.load = load
+}
+
+// SetTemperature changes the measured temperature in °C.
+func (s *Switch) SetTemperature(temperature float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.temperature = temperature
+}
+
+// account adds the energy consumed since the last change, s.mu must be held.
+func (s *Switch) account(now time.Time) {
+	s.energy += s.power() * now.Sub(s.updated).Seconds()
+	s.updated = now
+}
+
+// power returns the current power consumption, s.mu must be held.
+func (s *Switch) power() float64 {
+	if s.relay {
+		return s.load
+	}
+	return 0
+}
+
+// setRelay changes the relay state, s.mu must be held.
+func (s *Switch) setRelay(on bool) {
+	s.account(time.Now())
+	s.relay = on
+}
+
+// schedule sets the relay to state after d, replacing a pending timer. s.mu must be held.
+func (s *Switch) schedule(d time.Duration, state bool) {
+	s.cancel()
+	s.timer = time.AfterFunc(d, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.setRelay(state)
+		s.timer = nil
+	})
+}
+
+// cancel stops a pending timer, s.mu must be held.
+func (s *Switch) cancel() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Switch) handleToggle(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.setRelay(!s.relay)
+	relay := s.relay
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]bool{"relay": relay})
+}
+
+func (s *Switch) handleRelay(w http.ResponseWriter, r *http.Request) {
+	var on bool
+	switch r.URL.Query().Get("state") {
+	case string(mystrom.RelaySwitchStateOn):
+		on = true
+	case string(mystrom.RelaySwitchStateOff):
+		on = false
+	default:
+		http.Error(w, "invalid state", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.setRelay(on)
+	s.mu.Unlock()
+}
+
+func (s *Switch) handleReport(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+
+	s.mu.Lock()
+	s.account(now)
+	report := mystrom.SwitchReport{
+		Power:           s.power(),
+		WattsPerSecond:  s.power(),
+		Relay:           s.relay,
+		Temperature:     s.temperature,
+		BootID:          s.bootID,
+		EnergySinceBoot: s.energy,
+		TimeSinceBoot:   int64(now.Sub(s.boot).Seconds()),
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, report)
+}
+
+func (s *Switch) handleTemperature(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	measured := s.temperature
+	s.mu.Unlock()
+
+	// the relay heats up the measurement
+	const compensation = 3.0
+	writeJSON(w, mystrom.SwitchTemperature{
+		Measured:     measured,
+		Compensation: compensation,
+		Compensated:  measured - compensation,
+	})
+}
+
+func seconds(r *http.Request, max int) (time.Duration, bool) {
+	n, err := strconv.Atoi(r.URL.Query().Get("time"))
+	if err != nil || n < 0 || n > max {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+func (s *Switch) handlePowerCycle(w http.ResponseWriter, r *http.Request) {
+	wait, ok := seconds(r, 3600)
+	if !ok {
+		http.Error(w, "invalid time", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.relay {
+		http.Error(w, "relay is off", http.StatusBadRequest)
+		return
+	}
+
+	s.setRelay(false)
+	s.schedule(wait, true)
+}
+
+func (s *Switch) handleTimer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mode := mystrom.SwitchTimerMode(r.URL.Query().Get("mode"))
+	d, ok := seconds(r, 24*60*60)
+	if !ok && mode != mystrom.SwitchTimerModeNone {
+		http.Error(w, "invalid time", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch mode {
+	case mystrom.SwitchTimerModeOn:
+		s.setRelay(true)
+		s.schedule(d, false)
+	case mystrom.SwitchTimerModeOff:
+		s.setRelay(false)
+		s.schedule(d, true)
+	case mystrom.SwitchTimerModeToggle:
+		s.setRelay(!s.relay)
+		s.schedule(d, !s.relay)
+	case mystrom.SwitchTimerModeNone:
+		s.cancel()
+	default:
+		http.Error(w, "invalid mode", http.StatusBadRequest)
+	}
+}
+
+func (s *Switch) handleInfo(w http.ResponseWriter, r *http.Request) {
+	ip := ""
+	if host, _, err := net.SplitHostPort(r.Host); err == nil {
+		ip = host
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"version":   s.version,
+		"mac":       strings.ToUpper(strings.ReplaceAll(s.mac.String(), ":", "")),
+		"type":      int(s.typ),
+		"ssid":      "mystromtest",
+		"ip":        ip,
+		"mask":      "255.255.255.0",
+		"gw":        "",
+		"dns":       "",
+		"static":    false,
+		"connected": true,
+	})
+}
+
+func (s *Switch) handleSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		update := mystrom.SwitchSettingsUpdate{}
+		err := json.NewDecoder(r.Body).Decode(&update)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		if update.Name != nil {
+			s.settings.Name = *update.Name
+		}
+		if update.LED != nil {
+			s.settings.LED = *update.LED
+		}
+		if update.Restore != nil {
+			s.settings.Restore = *update.Restore
+		}
+		if update.PanelLock != nil {
+			s.settings.PanelLock = *update.PanelLock
+		}
+		s.mu.Unlock()
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, s.Settings())
+}
+
+func (s *Switch) handleReboot(_ http.ResponseWriter, _ *http.Request) {
+	s.Reboot()
+}
+
+func (s *Switch) handleReset(_ http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings = mystrom.SwitchSettings{LED: true}
+	s.setRelay(false)
+	s.reboot()
+}
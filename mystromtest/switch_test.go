@@ -0,0 +1,209 @@
+package mystromtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func newSwitch(t *testing.T, cfg mystromtest.SwitchConfig) (*mystromtest.Switch, *mystrom.Switch) {
+	t.Helper()
+
+	sim := mystromtest.NewSwitch(cfg)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sim, mystrom.NewSwitch(baseURL)
+}
+
+func TestSwitch_Relay(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sim, sw := newSwitch(t, mystromtest.SwitchConfig{Load: 60})
+
+	if err := sw.On(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.Relay() {
+		t.Error("expected relay to be on")
+	}
+
+	report, err := sw.Report(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Relay || report.Power != 60 {
+		t.Errorf("Switch.Report() = %+v, want relay on with 60W", report)
+	}
+
+	if err := sw.Toggle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Relay() {
+		t.Error("expected relay to be off after toggle")
+	}
+
+	report, err = sw.ReportV1(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Relay || report.Power != 0 || report.EnergySinceBoot <= 0 {
+		t.Errorf("Switch.ReportV1() = %+v, want relay off with consumed energy", report)
+	}
+}
+
+func TestSwitch_Temperature(t *testing.T) {
+	t.Parallel()
+
+	sim, sw := newSwitch(t, mystromtest.SwitchConfig{})
+	sim.SetTemperature(30)
+
+	temp, err := sw.Temperature(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp.Measured != 30 || temp.Compensated != temp.Measured-temp.Compensation {
+		t.Errorf("Switch.Temperature() = %+v", temp)
+	}
+}
+
+func TestSwitch_Info(t *testing.T) {
+	t.Parallel()
+
+	_, sw := newSwitch(t, mystromtest.SwitchConfig{Type: mystrom.DeviceTypeSwitchCH})
+
+	info, err := sw.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != mystrom.DeviceTypeSwitchCH || info.MAC.String() != "5c:cf:7f:00:00:01" {
+		t.Errorf("Switch.Info() = %+v", info)
+	}
+}
+
+func TestSwitch_PowerCycle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sim, sw := newSwitch(t, mystromtest.SwitchConfig{})
+
+	if err := sw.PowerCycle(ctx, time.Second); !errors.Is(err, mystrom.ErrPowerCycleRelayOff) {
+		t.Errorf("Switch.PowerCycle() error = %v, want %v", err, mystrom.ErrPowerCycleRelayOff)
+	}
+
+	if err := sw.On(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.PowerCycle(ctx, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Relay() {
+		t.Error("expected relay to be off during power cycle")
+	}
+
+	waitRelay(t, sim, true)
+}
+
+func TestSwitch_Timer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sim, sw := newSwitch(t, mystromtest.SwitchConfig{})
+
+	if err := sw.Timer(ctx, mystrom.SwitchTimerModeOn, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.Relay() {
+		t.Error("expected relay to be on while the timer runs")
+	}
+
+	waitRelay(t, sim, false)
+
+	if err := sw.Timer(ctx, mystrom.SwitchTimerModeOn, 48*time.Hour); !errors.Is(err, mystrom.ErrTimerOutOfRange) {
+		t.Errorf("Switch.Timer() error = %v, want %v", err, mystrom.ErrTimerOutOfRange)
+	}
+}
+
+func waitRelay(t *testing.T, sim *mystromtest.Switch, want bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for sim.Relay() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("relay did not change to %v", want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSwitch_Settings(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sim, sw := newSwitch(t, mystromtest.SwitchConfig{})
+
+	settings, err := sw.Settings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := *settings
+	policy.Restore = true
+	if err := sw.UpdateSettings(ctx, policy.Diff(*settings)); err != nil {
+		t.Fatal(err)
+	}
+	if got := sim.Settings(); got != policy {
+		t.Errorf("Switch.Settings() = %+v, want %+v", got, policy)
+	}
+
+	if err := sw.SetSettings(ctx, mystrom.SwitchSettings{Name: "Kitchen"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sim.Settings(), (mystrom.SwitchSettings{Name: "Kitchen"}); got != want {
+		t.Errorf("Switch.Settings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSwitch_Reboot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sim, sw := newSwitch(t, mystromtest.SwitchConfig{Relay: true, BootTime: 100 * time.Millisecond})
+
+	if err := sw.Reboot(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sw.Report(ctx); !errors.Is(err, mystrom.ErrStatus) {
+		t.Errorf("Switch.Report() error = %v while booting, want %v", err, mystrom.ErrStatus)
+	}
+	if sim.Relay() {
+		t.Error("expected relay to be off after reboot without restore")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	policy := sim.Settings()
+	policy.Restore = true
+	if err := sw.UpdateSettings(ctx, policy.Diff(sim.Settings())); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.On(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	sim.Reboot()
+	if !sim.Relay() {
+		t.Error("expected relay to be restored after reboot")
+	}
+}
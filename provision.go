@@ -0,0 +1,169 @@
+package mystrom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AccessPointURL is the base URL of an unconfigured device in access point mode.
+var AccessPointURL = &url.URL{Scheme: "http", Host: "192.168.254.1"}
+
+// WiFiNetwork is a wireless network visible to a device.
+type WiFiNetwork struct {
+	SSID   string
+	Signal int // signal strength in dBm
+}
+
+// wifiNetworks decodes the scan response, a flat list alternating
+// between SSID and signal strength.
+type wifiNetworks []WiFiNetwork
+
+func (n *wifiNetworks) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	if len(raw)%2 != 0 {
+		return fmt.Errorf("invalid scan response with %d elements", len(raw))
+	}
+
+	networks := make(wifiNetworks, 0, len(raw)/2)
+	for i := 0; i < len(raw); i += 2 {
+		network := WiFiNetwork{}
+		err = json.Unmarshal(raw[i], &network.SSID)
+		if err != nil {
+			return fmt.Errorf("invalid ssid: %w", err)
+		}
+		err = json.Unmarshal(raw[i+1], &network.Signal)
+		if err != nil {
+			return fmt.Errorf("invalid signal strength of %s: %w", network.SSID, err)
+		}
+		networks = append(networks, network)
+	}
+
+	*n = networks
+	return nil
+}
+
+// ScanWiFi returns the wireless networks visible to the device.
+func (c *Client) ScanWiFi(ctx context.Context, baseURL *url.URL) ([]WiFiNetwork, error) {
+	networks := wifiNetworks{}
+
+	req, err := c.newRequest(ctx, baseURL, http.MethodGet, "api/v1/scan", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.doJSON(req, &networks)
+	return networks, err
+}
+
+// WiFiConfig configures the wireless network a device connects to. The
+// network is configured by DHCP unless IP is set.
+type WiFiConfig struct {
+	SSID     string `json:"ssid"`
+	Password string `json:"passwd"`
+	IP       net.IP `json:"ip,omitempty"`
+	Mask     net.IP `json:"mask,omitempty"`
+	Gateway  net.IP `json:"gw,omitempty"`
+	DNS      net.IP `json:"dns,omitempty"`
+}
+
+// Validate checks if the configuration is complete.
+func (w WiFiConfig) Validate() error {
+	if w.SSID == "" {
+		return errors.New("ssid is required")
+	}
+
+	if w.IP == nil {
+		if w.Mask != nil || w.Gateway != nil || w.DNS != nil {
+			return errors.New("mask, gateway and dns require a static ip")
+		}
+		return nil
+	}
+
+	if w.Mask == nil || w.Gateway == nil {
+		return errors.New("static ip requires mask and gateway")
+	}
+	return nil
+}
+
+// ConnectWiFi configures the device to connect to a wireless network. The
+// device leaves access point mode and restarts its network afterwards.
+func (c *Client) ConnectWiFi(ctx context.Context, baseURL *url.URL, config WiFiConfig) error {
+	err := config.Validate()
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, baseURL, http.MethodPost, "api/v1/connect", nil, config)
+	if err != nil {
+		return err
+	}
+
+	return c.doDiscard(req)
+}
+
+// WaitOnline blocks until the device with the given MAC address can be
+// resolved and its info is served, e.g. after it was provisioned or
+// restarted. The device is resolved again after every failed attempt, as
+// it may not be known yet or come back with a new address. Failing
+// attempts are repeated until ctx is done.
+func (c *Client) WaitOnline(ctx context.Context, resolver Resolver, mac net.HardwareAddr) (*url.URL, *DeviceInfo, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var baseURL *url.URL
+	for {
+		u, err := resolver.Resolve(ctx, mac)
+		if err == nil {
+			baseURL = u
+
+			var info *DeviceInfo
+			info, err = c.Info(ctx, baseURL)
+			if err == nil && info.MAC.String() == mac.String() {
+				return baseURL, info, nil
+			}
+			if err == nil {
+				err = fmt.Errorf("device at %s has mac address %s", baseURL.Host, info.MAC)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return baseURL, nil, fmt.Errorf("device %s did not come online: %w", mac, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Provision connects the device in access point mode at baseURL to a
+// wireless network and waits until it is online on that network. The
+// resolver has to see the target network, e.g. a Discover on a second
+// interface, see ConnectWiFi and WaitOnline to switch networks in between.
+func (c *Client) Provision(
+	ctx context.Context,
+	baseURL *url.URL,
+	config WiFiConfig,
+	resolver Resolver,
+) (*url.URL, *DeviceInfo, error) {
+	info, err := c.Info(ctx, baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = c.ConnectWiFi(ctx, baseURL, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.WaitOnline(ctx, resolver, info.MAC)
+}
@@ -0,0 +1,208 @@
+package mystrom_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func TestClient_ScanWiFi(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []mystrom.WiFiNetwork
+		wantErr bool
+	}{
+		{
+			name: "success",
+			body: `["Home", -48, "Guest", -71]`,
+			want: []mystrom.WiFiNetwork{{SSID: "Home", Signal: -48}, {SSID: "Guest", Signal: -71}},
+		},
+		{
+			name: "empty",
+			body: `[]`,
+			want: []mystrom.WiFiNetwork{},
+		},
+		{
+			name:    "odd",
+			body:    `["Home", -48, "Guest"]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/scan" {
+					t.Errorf("expected /api/v1/scan path, got %s", r.URL.Path)
+				}
+
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			networks, err := mystrom.NewClient().ScanWiFi(context.Background(), baseURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.ScanWiFi() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(networks, tt.want) {
+				t.Errorf("Client.ScanWiFi() = %v, want %v", networks, tt.want)
+			}
+		})
+	}
+}
+
+func TestWiFiConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	ip := net.IPv4(192, 168, 1, 20)
+	mask := net.IPv4(255, 255, 255, 0)
+	gw := net.IPv4(192, 168, 1, 1)
+
+	tests := []struct {
+		name    string
+		config  mystrom.WiFiConfig
+		wantErr bool
+	}{
+		{name: "dhcp", config: mystrom.WiFiConfig{SSID: "Home", Password: "secret"}},
+		{name: "static", config: mystrom.WiFiConfig{SSID: "Home", IP: ip, Mask: mask, Gateway: gw}},
+		{name: "no ssid", config: mystrom.WiFiConfig{Password: "secret"}, wantErr: true},
+		{name: "static without gateway", config: mystrom.WiFiConfig{SSID: "Home", IP: ip, Mask: mask}, wantErr: true},
+		{name: "gateway without ip", config: mystrom.WiFiConfig{SSID: "Home", Gateway: gw}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("WiFiConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_Provision(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x07}
+	device := mystromtest.NewSwitch(mystromtest.SwitchConfig{MAC: mac})
+
+	var config map[string]string
+	ap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/connect" {
+			if r.Method != http.MethodPost {
+				t.Errorf("expected POST method, got %s", r.Method)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		device.ServeHTTP(w, r)
+	}))
+	defer ap.Close()
+
+	station := httptest.NewServer(device)
+	defer station.Close()
+
+	apURL, err := url.Parse(ap.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stationURL, err := url.Parse(station.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := mystrom.ResolverFunc(func(_ context.Context, m net.HardwareAddr) (*url.URL, error) {
+		if m.String() != mac.String() {
+			t.Errorf("expected to resolve %s, got %s", mac, m)
+		}
+		return stationURL, nil
+	})
+
+	wifi := mystrom.WiFiConfig{SSID: "Home", Password: "secret"}
+	u, info, err := mystrom.NewClient().Provision(ctx, apURL, wifi, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[string]string{"ssid": "Home", "passwd": "secret"}; !reflect.DeepEqual(config, want) {
+		t.Errorf("expected connect body %v, got %v", want, config)
+	}
+	if u.String() != stationURL.String() {
+		t.Errorf("Client.Provision() url = %s, want %s", u, stationURL)
+	}
+	if info.MAC.String() != mac.String() {
+		t.Errorf("Client.Provision() mac = %s, want %s", info.MAC, mac)
+	}
+}
+
+func TestClient_WaitOnline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x08}
+	station := httptest.NewServer(mystromtest.NewSwitch(mystromtest.SwitchConfig{MAC: mac}))
+	defer station.Close()
+
+	stale := httptest.NewServer(http.NotFoundHandler())
+	stale.Close()
+
+	stationURL, err := url.Parse(station.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleURL, err := url.Parse(stale.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	resolver := mystrom.ResolverFunc(func(_ context.Context, _ net.HardwareAddr) (*url.URL, error) {
+		calls++
+		switch calls {
+		case 1:
+			return nil, mystrom.ErrNotFound
+		case 2:
+			return staleURL, nil
+		default:
+			return stationURL, nil
+		}
+	})
+
+	u, info, err := mystrom.NewClient().WaitOnline(ctx, resolver, mac)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 resolutions, got %d", calls)
+	}
+	if u.String() != stationURL.String() {
+		t.Errorf("Client.WaitOnline() url = %s, want %s", u, stationURL)
+	}
+	if info.MAC.String() != mac.String() {
+		t.Errorf("Client.WaitOnline() mac = %s, want %s", info.MAC, mac)
+	}
+}
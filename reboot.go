@@ -0,0 +1,165 @@
+package mystrom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FactoryResetConfirmation has to be passed to FactoryReset to confirm
+// that all settings of the device should be erased.
+const FactoryResetConfirmation = "erase all settings"
+
+// ErrFactoryResetNotConfirmed is returned by FactoryReset without a valid confirmation.
+var ErrFactoryResetNotConfirmed = errors.New("factory reset not confirmed")
+
+// Reboot restarts the device. It is not idempotent.
+func (c *Client) Reboot(ctx context.Context, baseURL *url.URL) error {
+	req, err := c.newRequest(ctx, baseURL, http.MethodGet, "api/v1/reboot", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.doDiscard(nonIdempotent(req))
+}
+
+// FactoryReset erases all settings of the device including its network
+// configuration, afterwards the device restarts in access point mode.
+// confirm has to be FactoryResetConfirmation.
+func (c *Client) FactoryReset(ctx context.Context, baseURL *url.URL, confirm string) error {
+	if confirm != FactoryResetConfirmation {
+		return ErrFactoryResetNotConfirmed
+	}
+
+	req, err := c.newRequest(ctx, baseURL, http.MethodGet, "api/v1/reset", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.doDiscard(nonIdempotent(req))
+}
+
+// RebootAndWait restarts the device and blocks until it is back: it waits
+// for the device to stop responding, then for a new beacon of its MAC
+// address from the resolver and a response of its info. A resolver that
+// implements SinceResolver, e.g. a Registry or Discover, only resolves
+// beacons received after the reboot, others have to wait for a new beacon.
+func (c *Client) RebootAndWait(ctx context.Context, baseURL *url.URL, resolver Resolver) (*url.URL, *DeviceInfo, error) {
+	info, err := c.Info(ctx, baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rebooted := time.Now()
+	err = c.Reboot(ctx, baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = c.waitDown(ctx, baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.WaitOnline(ctx, resolveSince(resolver, rebooted), info.MAC)
+}
+
+// waitDown blocks until the device at baseURL stops responding.
+func (c *Client) waitDown(ctx context.Context, baseURL *url.URL) error {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		_, err := c.Info(ctx, baseURL)
+		if err != nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("device at %s did not restart: %w", baseURL.Host, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reboot restarts the Switch. It is not idempotent.
+func (s Switch) Reboot(ctx context.Context) error {
+	req, err := s.newRequest(ctx, http.MethodGet, "api/v1/reboot", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.do(nonIdempotent(req))
+}
+
+// FactoryReset erases all settings of the Switch, see Client.FactoryReset.
+func (s Switch) FactoryReset(ctx context.Context, confirm string) error {
+	if confirm != FactoryResetConfirmation {
+		return ErrFactoryResetNotConfirmed
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, "api/v1/reset", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.do(nonIdempotent(req))
+}
+
+// RebootAndWait restarts the Switch and blocks until it is back, see Client.RebootAndWait.
+func (s Switch) RebootAndWait(ctx context.Context, resolver Resolver) (*url.URL, *DeviceInfo, error) {
+	baseURL := s.baseURL
+	if s.target != nil {
+		var err error
+		baseURL, err = s.target.url(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return s.client.RebootAndWait(ctx, baseURL, resolver)
+}
+
+// Reboot restarts the Bulb. It is not idempotent.
+func (b *Bulb) Reboot(ctx context.Context) error {
+	return b.device.client.Reboot(ctx, b.device.baseURL)
+}
+
+// FactoryReset erases all settings of the Bulb, see Client.FactoryReset.
+func (b *Bulb) FactoryReset(ctx context.Context, confirm string) error {
+	return b.device.client.FactoryReset(ctx, b.device.baseURL, confirm)
+}
+
+// Reboot restarts the LED Strip. It is not idempotent.
+func (l *LEDStrip) Reboot(ctx context.Context) error {
+	return l.device.client.Reboot(ctx, l.device.baseURL)
+}
+
+// FactoryReset erases all settings of the LED Strip, see Client.FactoryReset.
+func (l *LEDStrip) FactoryReset(ctx context.Context, confirm string) error {
+	return l.device.client.FactoryReset(ctx, l.device.baseURL, confirm)
+}
+
+// Reboot restarts the Button. It is not idempotent.
+func (b *Button) Reboot(ctx context.Context) error {
+	return b.device.client.Reboot(ctx, b.device.baseURL)
+}
+
+// FactoryReset erases all settings of the Button, see Client.FactoryReset.
+func (b *Button) FactoryReset(ctx context.Context, confirm string) error {
+	return b.device.client.FactoryReset(ctx, b.device.baseURL, confirm)
+}
+
+// Reboot restarts the Motion Sensor. It is not idempotent.
+func (m MotionSensor) Reboot(ctx context.Context) error {
+	return m.client.Reboot(ctx, m.baseURL)
+}
+
+// FactoryReset erases all settings of the Motion Sensor, see Client.FactoryReset.
+func (m MotionSensor) FactoryReset(ctx context.Context, confirm string) error {
+	return m.client.FactoryReset(ctx, m.baseURL, confirm)
+}
@@ -0,0 +1,207 @@
+package mystrom_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+	"thde.io/mystrom/mystromtest"
+)
+
+func TestReboot(t *testing.T) {
+	t.Parallel()
+
+	confirm := mystrom.FactoryResetConfirmation
+	tests := []struct {
+		name string
+		call func(*mystrom.Client, *url.URL) error
+		want string
+	}{
+		{
+			name: "client reboot",
+			call: func(c *mystrom.Client, u *url.URL) error { return c.Reboot(context.Background(), u) },
+			want: "/api/v1/reboot",
+		},
+		{
+			name: "client factory reset",
+			call: func(c *mystrom.Client, u *url.URL) error { return c.FactoryReset(context.Background(), u, confirm) },
+			want: "/api/v1/reset",
+		},
+		{
+			name: "switch reboot",
+			call: func(c *mystrom.Client, u *url.URL) error { return c.NewSwitch(u).Reboot(context.Background()) },
+			want: "/api/v1/reboot",
+		},
+		{
+			name: "switch factory reset",
+			call: func(c *mystrom.Client, u *url.URL) error {
+				return c.NewSwitch(u).FactoryReset(context.Background(), confirm)
+			},
+			want: "/api/v1/reset",
+		},
+		{
+			name: "bulb reboot",
+			call: func(c *mystrom.Client, u *url.URL) error { return c.NewBulb(u).Reboot(context.Background()) },
+			want: "/api/v1/reboot",
+		},
+		{
+			name: "led strip factory reset",
+			call: func(c *mystrom.Client, u *url.URL) error {
+				return c.NewLEDStrip(u).FactoryReset(context.Background(), confirm)
+			},
+			want: "/api/v1/reset",
+		},
+		{
+			name: "button reboot",
+			call: func(c *mystrom.Client, u *url.URL) error { return c.NewButton(u).Reboot(context.Background()) },
+			want: "/api/v1/reboot",
+		},
+		{
+			name: "motion sensor reboot",
+			call: func(c *mystrom.Client, u *url.URL) error { return c.NewMotionSensor(u).Reboot(context.Background()) },
+			want: "/api/v1/reboot",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.want {
+					t.Errorf("expected %s path, got %s", tt.want, r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.call(mystrom.NewClient(), baseURL)
+			if err != nil {
+				t.Errorf("%s error = %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestFactoryReset_notConfirmed(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := mystrom.NewClient()
+	if err := client.FactoryReset(context.Background(), baseURL, "yes"); !errors.Is(err, mystrom.ErrFactoryResetNotConfirmed) {
+		t.Errorf("Client.FactoryReset() error = %v, want %v", err, mystrom.ErrFactoryResetNotConfirmed)
+	}
+	if err := client.NewSwitch(baseURL).FactoryReset(context.Background(), ""); !errors.Is(err, mystrom.ErrFactoryResetNotConfirmed) {
+		t.Errorf("Switch.FactoryReset() error = %v, want %v", err, mystrom.ErrFactoryResetNotConfirmed)
+	}
+}
+
+func TestSwitch_RebootAndWait(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x08}
+	ts := httptest.NewServer(mystromtest.NewSwitch(mystromtest.SwitchConfig{MAC: mac, BootTime: 300 * time.Millisecond}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sw := mystrom.NewSwitch(baseURL)
+	before, err := sw.ReportV1(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := false
+	resolver := mystrom.ResolverFunc(func(_ context.Context, m net.HardwareAddr) (*url.URL, error) {
+		resolved = m.String() == mac.String()
+		return baseURL, nil
+	})
+
+	_, info, err := sw.RebootAndWait(ctx, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resolved || info.MAC.String() != mac.String() {
+		t.Errorf("Switch.RebootAndWait() = %v, resolved %v", info.MAC, resolved)
+	}
+
+	after, err := sw.ReportV1(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !after.Rebooted(*before) {
+		t.Errorf("expected boot id to change, got %s and %s", before.BootID, after.BootID)
+	}
+}
+
+// sinceResolver resolves stale until it is asked for beacons received after the reboot.
+type sinceResolver struct {
+	stale, fresh *url.URL
+	start        time.Time
+}
+
+func (r sinceResolver) Resolve(context.Context, net.HardwareAddr) (*url.URL, error) {
+	return r.stale, nil
+}
+
+func (r sinceResolver) ResolveSince(_ context.Context, _ net.HardwareAddr, since time.Time) (*url.URL, error) {
+	if !since.After(r.start) {
+		return r.stale, nil
+	}
+	return r.fresh, nil
+}
+
+func TestClient_RebootAndWait_since(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x09}
+	ts := httptest.NewServer(mystromtest.NewSwitch(mystromtest.SwitchConfig{MAC: mac, BootTime: 300 * time.Millisecond}))
+	defer ts.Close()
+
+	// the device moved to fresh, the old address still answers with another device
+	stale := httptest.NewServer(mystromtest.NewSwitch(mystromtest.SwitchConfig{MAC: net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x00, 0x00, 0x10}}))
+	defer stale.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleURL, err := url.Parse(stale.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := sinceResolver{stale: staleURL, fresh: baseURL, start: time.Now()}
+	u, info, err := mystrom.NewClient().RebootAndWait(ctx, baseURL, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.String() != baseURL.String() || info.MAC.String() != mac.String() {
+		t.Errorf("Client.RebootAndWait() = %s, %v, want %s, %v", u, info.MAC, baseURL, mac)
+	}
+}
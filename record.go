@@ -0,0 +1,200 @@
+package mystrom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNoRecording is returned by a ReplayTransport if no recorded
+// interaction is left for a request.
+var ErrNoRecording = errors.New("no recorded interaction")
+
+// Interaction is a single recorded request and its response, stored as one
+// line of JSON.
+type Interaction struct {
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestBody    string      `json:"request_body,omitempty"`
+	StatusCode     int         `json:"status_code,omitempty"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+	Error          string      `json:"error,omitempty"` // set if no response was received
+}
+
+// RecordingTransport is a http.RoundTripper which records every interaction
+// with a device as JSON lines. Passwords in JSON request bodies are
+// redacted. Use it with WithHTTPClient.
+type RecordingTransport struct {
+	next http.RoundTripper
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecordingTransport records all interactions sent through next to w.
+// If next is nil, http.DefaultTransport is used.
+func NewRecordingTransport(w io.Writer, next http.RoundTripper) *RecordingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &RecordingTransport{
+		next: next,
+		enc:  json.NewEncoder(w),
+	}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	interaction := Interaction{
+		Method: req.Method,
+		URL:    req.URL.String(),
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+
+		interaction.RequestBody = string(redactSecrets(body))
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		interaction.Error = err.Error()
+		return nil, t.record(interaction, err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction.StatusCode = resp.StatusCode
+	interaction.ResponseHeader = resp.Header
+	interaction.ResponseBody = string(body)
+
+	err = t.record(interaction, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *RecordingTransport) record(interaction Interaction, roundTripErr error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err := t.enc.Encode(interaction)
+	if err != nil {
+		return fmt.Errorf("error recording interaction: %w", err)
+	}
+	return roundTripErr
+}
+
+// ReplayTransport is a http.RoundTripper which serves interactions recorded
+// by a RecordingTransport. Requests are matched by method, path and query,
+// ignoring the host, and served in the order they were recorded.
+type ReplayTransport struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+}
+
+// NewReplayTransport reads recorded interactions from r.
+func NewReplayTransport(r io.Reader) (*ReplayTransport, error) {
+	t := ReplayTransport{interactions: map[string][]Interaction{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		err := json.Unmarshal(scanner.Bytes(), &interaction)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding interaction on line %d: %w", line, err)
+		}
+
+		req, err := http.NewRequest(interaction.Method, interaction.URL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing interaction on line %d: %w", line, err)
+		}
+
+		key := replayKey(req)
+		t.interactions[key] = append(t.interactions[key], interaction)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading interactions: %w", err)
+	}
+
+	return &t, nil
+}
+
+func replayKey(req *http.Request) string {
+	return req.Method + " " + req.URL.RequestURI()
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := replayKey(req)
+
+	t.mu.Lock()
+	queue := t.interactions[key]
+	if len(queue) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s", ErrNoRecording, key)
+	}
+	interaction := queue[0]
+	t.interactions[key] = queue[1:]
+	t.mu.Unlock()
+
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+
+	header := interaction.ResponseHeader
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(interaction.ResponseBody))),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of recorded interactions not served yet.
+func (t *ReplayTransport) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, queue := range t.interactions {
+		n += len(queue)
+	}
+	return n
+}
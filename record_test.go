@@ -0,0 +1,149 @@
+package mystrom_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"thde.io/mystrom"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	relay := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/toggle":
+			relay = !relay
+		case "/report":
+			if relay {
+				_, _ = w.Write([]byte(`{"power": 25.5, "relay": true}`))
+			} else {
+				_, _ = w.Write([]byte(`{"power": 0, "relay": false}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenario := func(s *mystrom.Switch) ([]*mystrom.SwitchReport, error) {
+		var reports []*mystrom.SwitchReport
+		for i := 0; i < 2; i++ {
+			report, err := s.Report(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, report)
+
+			if err := s.Toggle(context.Background()); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := s.Temperature(context.Background())
+		return reports, err
+	}
+
+	var recording bytes.Buffer
+	recorder := mystrom.NewRecordingTransport(&recording, nil)
+	recorded, recordErr := scenario(mystrom.NewClient(
+		mystrom.WithHTTPClient(&http.Client{Transport: recorder}),
+	).NewSwitch(baseURL))
+	if !errors.Is(recordErr, mystrom.ErrStatus) {
+		t.Fatalf("expected temperature to fail with status error, got %v", recordErr)
+	}
+
+	if lines := strings.Count(recording.String(), "\n"); lines != 5 {
+		t.Errorf("expected 5 recorded interactions, got %d:\n%s", lines, recording.String())
+	}
+
+	replayer, err := mystrom.NewReplayTransport(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the recorded device is not needed anymore
+	ts.Close()
+
+	offline, err := url.Parse("http://192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, replayErr := scenario(mystrom.NewClient(
+		mystrom.WithHTTPClient(&http.Client{Transport: replayer}),
+	).NewSwitch(offline))
+
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed reports %+v, want %+v", replayed, recorded)
+	}
+	if replayErr == nil || replayErr.Error() != recordErr.Error() {
+		t.Errorf("replayed error %v, want %v", replayErr, recordErr)
+	}
+	if replayer.Remaining() != 0 {
+		t.Errorf("expected all interactions to be replayed, %d left", replayer.Remaining())
+	}
+
+	_, err = mystrom.NewClient(
+		mystrom.WithHTTPClient(&http.Client{Transport: replayer}),
+	).NewSwitch(offline).Report(context.Background())
+	if !errors.Is(err, mystrom.ErrNoRecording) {
+		t.Errorf("expected %v, got %v", mystrom.ErrNoRecording, err)
+	}
+}
+
+func TestNewReplayTransport_invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := mystrom.NewReplayTransport(strings.NewReader("{\"method\": \"GET\", \"url\": \"http://a/report\"}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected decoding error on line 2, got %v", err)
+	}
+}
+
+func TestRecordingTransport_redact(t *testing.T) {
+	t.Parallel()
+
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var recording bytes.Buffer
+	client := mystrom.NewClient(mystrom.WithHTTPClient(&http.Client{
+		Transport: mystrom.NewRecordingTransport(&recording, nil),
+	}))
+	err = client.ConnectWiFi(context.Background(), baseURL, mystrom.WiFiConfig{SSID: "Home", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "secret") {
+		t.Errorf("expected the device to receive the password, got %s", body)
+	}
+	if strings.Contains(recording.String(), "secret") || !strings.Contains(recording.String(), "REDACTED") {
+		t.Errorf("expected the password to be redacted, got %s", recording.String())
+	}
+}
@@ -0,0 +1,279 @@
+package mystrom
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeviceStatus is the reachability of a device derived from its beacons.
+type DeviceStatus int
+
+const (
+	DeviceOnline DeviceStatus = iota + 1
+	DeviceOffline
+)
+
+func (s DeviceStatus) String() string {
+	switch s {
+	case DeviceOnline:
+		return "online"
+	case DeviceOffline:
+		return "offline"
+	default:
+		return fmt.Sprintf("%d", s)
+	}
+}
+
+// RegistryEntry holds the latest known state of a device.
+type RegistryEntry struct {
+	Device
+
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Status    DeviceStatus
+}
+
+// IP returns the IP address the last beacon was sent from.
+func (e RegistryEntry) IP() net.IP {
+	return net.ParseIP(addrHost(e.Address))
+}
+
+// RegistryEventType describes a change in the Registry.
+type RegistryEventType int
+
+const (
+	RegistryAdded   RegistryEventType = iota + 1 // first beacon of a device
+	RegistryUpdated                              // address, type or flags of a device changed
+	RegistryOnline                               // beacon of an offline device
+	RegistryOffline                              // no beacon within OfflineAfter
+	RegistryEvicted                              // no beacon within EvictAfter, the entry was removed
+)
+
+func (t RegistryEventType) String() string {
+	switch t {
+	case RegistryAdded:
+		return "added"
+	case RegistryUpdated:
+		return "updated"
+	case RegistryOnline:
+		return "online"
+	case RegistryOffline:
+		return "offline"
+	case RegistryEvicted:
+		return "evicted"
+	default:
+		return fmt.Sprintf("%d", t)
+	}
+}
+
+// RegistryEvent is sent to subscribers of a Registry.
+type RegistryEvent struct {
+	Type  RegistryEventType
+	Entry RegistryEntry
+}
+
+// Registry tracks discovered devices by MAC address. It is safe for
+// concurrent use, the zero value is ready to use.
+type Registry struct {
+	// OfflineAfter is the time without a beacon after which a device is
+	// considered offline, defaults to three beacon intervals.
+	OfflineAfter time.Duration
+	// EvictAfter is the time without a beacon after which a device is
+	// removed, defaults to one hour.
+	EvictAfter time.Duration
+
+	mu          sync.RWMutex
+	entries     map[string]*RegistryEntry
+	subscribers map[chan RegistryEvent]struct{}
+}
+
+func (r *Registry) offlineAfter() time.Duration {
+	if r.OfflineAfter > 0 {
+		return r.OfflineAfter
+	}
+	return 3 * BeaconInterval
+}
+
+func (r *Registry) evictAfter() time.Duration {
+	if r.EvictAfter > 0 {
+		return r.EvictAfter
+	}
+	return time.Hour
+}
+
+// Run feeds the Registry with beacons received by d until ctx is canceled.
+func (r *Registry) Run(ctx context.Context, d *Discover) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		ticker := time.NewTicker(BeaconInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				r.Sweep(now)
+			}
+		}
+	}()
+
+	return d.Each(ctx, func(device Device, err error) {
+		if err == nil {
+			r.Observe(device)
+		}
+	})
+}
+
+// Observe records a beacon of device received now, see Sweep to mark
+// devices offline when feeding the Registry without Run.
+func (r *Registry) Observe(device Device) {
+	r.observe(device, time.Now())
+}
+
+func (r *Registry) observe(device Device, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entries == nil {
+		r.entries = map[string]*RegistryEntry{}
+	}
+
+	key := device.MAC.String()
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &RegistryEntry{Device: device, FirstSeen: now, LastSeen: now, Status: DeviceOnline}
+		r.entries[key] = entry
+		r.publish(RegistryEvent{Type: RegistryAdded, Entry: *entry})
+		return
+	}
+
+	changed := !entry.Device.equal(device)
+	offline := entry.Status == DeviceOffline
+
+	entry.Device = device
+	entry.LastSeen = now
+	entry.Status = DeviceOnline
+
+	if offline {
+		r.publish(RegistryEvent{Type: RegistryOnline, Entry: *entry})
+	}
+	if changed {
+		r.publish(RegistryEvent{Type: RegistryUpdated, Entry: *entry})
+	}
+}
+
+// Sweep marks devices without a beacon since OfflineAfter offline and
+// evicts the ones without a beacon since EvictAfter. Run sweeps every
+// beacon interval, callers feeding the Registry with Observe have to call
+// Sweep periodically themselves.
+func (r *Registry) Sweep(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, entry := range r.entries {
+		silent := now.Sub(entry.LastSeen)
+
+		if silent >= r.evictAfter() {
+			delete(r.entries, key)
+			r.publish(RegistryEvent{Type: RegistryEvicted, Entry: *entry})
+			continue
+		}
+
+		if silent >= r.offlineAfter() && entry.Status == DeviceOnline {
+			entry.Status = DeviceOffline
+			r.publish(RegistryEvent{Type: RegistryOffline, Entry: *entry})
+		}
+	}
+}
+
+// Lookup returns the entry of the device with the given MAC address.
+func (r *Registry) Lookup(mac net.HardwareAddr) (RegistryEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[mac.String()]
+	if !ok {
+		return RegistryEntry{}, false
+	}
+	return *entry, true
+}
+
+// LookupIP returns the entry of the device which last sent a beacon from ip.
+func (r *Registry) LookupIP(ip net.IP) (RegistryEntry, bool) {
+	for _, entry := range r.All() {
+		if entry.IP().Equal(ip) {
+			return entry, true
+		}
+	}
+	return RegistryEntry{}, false
+}
+
+// ByType returns the entries of all devices of the given types.
+func (r *Registry) ByType(types ...DeviceType) []RegistryEntry {
+	var entries []RegistryEntry
+	for _, entry := range r.All() {
+		for _, t := range types {
+			if entry.Type == t {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// All returns the entries of all devices sorted by MAC address.
+func (r *Registry) All() []RegistryEntry {
+	r.mu.RLock()
+	entries := make([]RegistryEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, *entry)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].MAC.String() < entries[j].MAC.String()
+	})
+	return entries
+}
+
+// Subscribe returns a channel receiving all changes of the Registry until
+// ctx is canceled. Events are dropped if the subscriber does not keep up.
+func (r *Registry) Subscribe(ctx context.Context) <-chan RegistryEvent {
+	ch := make(chan RegistryEvent, 16)
+
+	r.mu.Lock()
+	if r.subscribers == nil {
+		r.subscribers = map[chan RegistryEvent]struct{}{}
+	}
+	r.subscribers[ch] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		r.mu.Lock()
+		delete(r.subscribers, ch)
+		close(ch)
+		r.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// publish must be called with r.mu held.
+func (r *Registry) publish(e RegistryEvent) {
+	for ch := range r.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package mystrom
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	r := Registry{OfflineAfter: 15 * time.Second, EvictAfter: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := r.Subscribe(ctx)
+
+	next := func(want RegistryEventType) RegistryEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Type != want {
+				t.Fatalf("expected %s event, got %s", want, e.Type)
+			}
+			return e
+		default:
+			t.Fatalf("expected %s event, got none", want)
+		}
+		return RegistryEvent{}
+	}
+
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sw := Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 20), Port: 7979},
+		MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x56},
+		Type:    DeviceTypeSwitchEU,
+	}
+	bulb := Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 30), Port: 7979},
+		MAC:     net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x57},
+		Type:    DeviceTypeBulb,
+	}
+
+	r.observe(sw, start)
+	e := next(RegistryAdded)
+	if e.Entry.Status != DeviceOnline || !e.Entry.FirstSeen.Equal(start) {
+		t.Errorf("unexpected entry %+v", e.Entry)
+	}
+	r.observe(bulb, start)
+	next(RegistryAdded)
+
+	// same beacon only updates the last seen time
+	r.observe(sw, start.Add(5*time.Second))
+	if len(events) != 0 {
+		t.Errorf("expected no event, got %d", len(events))
+	}
+
+	// new DHCP lease
+	moved := sw
+	moved.Address = &net.UDPAddr{IP: net.IPv4(192, 168, 1, 21), Port: 7979}
+	r.observe(moved, start.Add(10*time.Second))
+	next(RegistryUpdated)
+
+	entry, ok := r.Lookup(sw.MAC)
+	if !ok || !entry.IP().Equal(net.IPv4(192, 168, 1, 21)) {
+		t.Errorf("Registry.Lookup() = %+v, %v", entry, ok)
+	}
+	if !entry.FirstSeen.Equal(start) || !entry.LastSeen.Equal(start.Add(10*time.Second)) {
+		t.Errorf("unexpected first/last seen %s/%s", entry.FirstSeen, entry.LastSeen)
+	}
+	if entry, ok := r.LookupIP(net.IPv4(192, 168, 1, 30)); !ok || entry.Type != DeviceTypeBulb {
+		t.Errorf("Registry.LookupIP() = %+v, %v", entry, ok)
+	}
+	if entries := r.ByType(DeviceTypeSwitchCH, DeviceTypeSwitchEU); len(entries) != 1 || entries[0].MAC.String() != sw.MAC.String() {
+		t.Errorf("Registry.ByType() = %+v", entries)
+	}
+
+	// bulb goes silent
+	r.Sweep(start.Add(20 * time.Second))
+	e = next(RegistryOffline)
+	if e.Entry.Type != DeviceTypeBulb {
+		t.Errorf("expected bulb to be offline, got %+v", e.Entry)
+	}
+	if entry, _ := r.Lookup(bulb.MAC); entry.Status != DeviceOffline {
+		t.Errorf("expected bulb to be offline, got %s", entry.Status)
+	}
+
+	r.observe(bulb, start.Add(25*time.Second))
+	next(RegistryOnline)
+
+	r.Sweep(start.Add(2 * time.Minute))
+	next(RegistryEvicted)
+	next(RegistryEvicted)
+	if len(r.All()) != 0 {
+		t.Errorf("expected empty registry, got %+v", r.All())
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("expected events to be closed")
+	}
+}
+
+func TestRegistry_Sweep(t *testing.T) {
+	r := Registry{OfflineAfter: time.Minute}
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0, 0, 1}
+
+	r.Observe(Device{MAC: mac, Type: DeviceTypeSwitchEU})
+	r.Sweep(time.Now().Add(2 * time.Minute))
+
+	if entry, _ := r.Lookup(mac); entry.Status != DeviceOffline {
+		t.Errorf("expected observed device to be offline after sweep, got %s", entry.Status)
+	}
+}
@@ -0,0 +1,207 @@
+package mystrom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned if a device could not be resolved.
+var ErrNotFound = errors.New("device not found")
+
+// Resolver resolves the base URL of a device by its MAC address.
+// A Registry resolves from the beacons it already received, a Discover
+// waits for a fresh beacon of the device.
+type Resolver interface {
+	Resolve(ctx context.Context, mac net.HardwareAddr) (*url.URL, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as Resolver.
+type ResolverFunc func(ctx context.Context, mac net.HardwareAddr) (*url.URL, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context, mac net.HardwareAddr) (*url.URL, error) {
+	return f(ctx, mac)
+}
+
+// Resolve returns the base URL of the device with the given MAC address
+// from the last received beacon.
+func (r *Registry) Resolve(_ context.Context, mac net.HardwareAddr) (*url.URL, error) {
+	entry, ok := r.Lookup(mac)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, mac)
+	}
+
+	return entry.URL()
+}
+
+// Resolve blocks until a beacon of the device with the given MAC address
+// has been received and returns its base URL.
+func (d *Discover) Resolve(ctx context.Context, mac net.HardwareAddr) (*url.URL, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var found *Device
+	err := d.Each(ctx, func(device Device, err error) {
+		if err == nil && found == nil && device.MAC.String() == mac.String() {
+			found = &device
+			cancel()
+		}
+	})
+	if found != nil {
+		return found.URL()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrNotFound, mac, err)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, mac)
+}
+
+// SinceResolver is implemented by resolvers that can ignore beacons received
+// before a point in time, e.g. to wait for a device to come back after a restart.
+type SinceResolver interface {
+	ResolveSince(ctx context.Context, mac net.HardwareAddr, since time.Time) (*url.URL, error)
+}
+
+// ResolveSince returns the base URL of the device with the given MAC address
+// if its last beacon was received after since.
+func (r *Registry) ResolveSince(_ context.Context, mac net.HardwareAddr, since time.Time) (*url.URL, error) {
+	entry, ok := r.Lookup(mac)
+	if !ok || !entry.LastSeen.After(since) {
+		return nil, fmt.Errorf("%w: %s since %s", ErrNotFound, mac, since.Format(time.RFC3339))
+	}
+
+	return entry.URL()
+}
+
+// ResolveSince is the same as Resolve, a Discover only receives beacons sent
+// after it was called.
+func (d *Discover) ResolveSince(ctx context.Context, mac net.HardwareAddr, _ time.Time) (*url.URL, error) {
+	return d.Resolve(ctx, mac)
+}
+
+// resolveSince limits resolver to beacons received after since if it
+// implements SinceResolver.
+func resolveSince(resolver Resolver, since time.Time) Resolver {
+	r, ok := resolver.(SinceResolver)
+	if !ok {
+		return resolver
+	}
+
+	return ResolverFunc(func(ctx context.Context, mac net.HardwareAddr) (*url.URL, error) {
+		return r.ResolveSince(ctx, mac, since)
+	})
+}
+
+// ResolveOption configures how a Switch created by NewSwitchByMAC resolves its address.
+type ResolveOption func(*macTarget)
+
+// WithResolveTTL limits how long a resolved address is cached. A ttl <= 0
+// disables the cache and resolves the address before every request.
+func WithResolveTTL(ttl time.Duration) ResolveOption {
+	return func(t *macTarget) {
+		t.ttl = ttl
+		t.expires = true
+	}
+}
+
+// macTarget caches the resolved address of a device.
+type macTarget struct {
+	mac      net.HardwareAddr
+	resolver Resolver
+	ttl      time.Duration
+	expires  bool
+
+	mu         sync.Mutex
+	baseURL    *url.URL
+	resolvedAt time.Time
+	pending    *resolveCall
+}
+
+// resolveCall is a resolution in progress shared by concurrent requests.
+type resolveCall struct {
+	done    chan struct{}
+	baseURL *url.URL
+	err     error
+}
+
+func (t *macTarget) url(ctx context.Context) (*url.URL, error) {
+	t.mu.Lock()
+	u := t.baseURL
+	cached := u != nil && (!t.expires || time.Now().Sub(t.resolvedAt) < t.ttl)
+	t.mu.Unlock()
+
+	if cached {
+		return u, nil
+	}
+	return t.resolve(ctx)
+}
+
+// refresh resolves the address again after failed could not be reached and
+// reports whether it changed.
+func (t *macTarget) refresh(ctx context.Context, failed *url.URL) (*url.URL, bool, error) {
+	u, err := t.resolve(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return u, u.Host != failed.Host, nil
+}
+
+// resolve resolves the address without holding t.mu, so a slow resolver
+// does not block current. Concurrent calls share a single resolution.
+func (t *macTarget) resolve(ctx context.Context) (*url.URL, error) {
+	t.mu.Lock()
+	call := t.pending
+	if call == nil {
+		call = &resolveCall{done: make(chan struct{})}
+		t.pending = call
+		t.mu.Unlock()
+
+		u, err := t.resolver.Resolve(ctx, t.mac)
+
+		t.mu.Lock()
+		if err == nil {
+			t.baseURL = u
+			t.resolvedAt = time.Now()
+		}
+		t.pending = nil
+		t.mu.Unlock()
+
+		call.baseURL, call.err = u, err
+		close(call.done)
+	} else {
+		t.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("error resolving %s: %w", t.mac, ctx.Err())
+		}
+	}
+
+	if call.err != nil {
+		return nil, fmt.Errorf("error resolving %s: %w", t.mac, call.err)
+	}
+	return call.baseURL, nil
+}
+
+func (t *macTarget) current() url.URL {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.baseURL == nil {
+		return url.URL{}
+	}
+	return *t.baseURL
+}
+
+// isDialError reports whether err occurred while connecting, in which case
+// the request has not been sent and can safely be sent again.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,255 @@
+package mystrom_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+func TestClient_NewSwitchByMAC(t *testing.T) {
+	t.Parallel()
+
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x56}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/relay" {
+			t.Errorf("expected /relay path, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// the address of the first lease is not reachable anymore
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	var mu sync.Mutex
+	var calls int
+	resolver := mystrom.ResolverFunc(func(_ context.Context, m net.HardwareAddr) (*url.URL, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if m.String() != mac.String() {
+			t.Errorf("expected %s to be resolved, got %s", mac, m)
+		}
+
+		calls++
+		if calls == 1 {
+			return url.Parse(gone.URL)
+		}
+		return url.Parse(ts.URL)
+	})
+
+	s := mystrom.NewClient().NewSwitchByMAC(mac, resolver)
+
+	if u := s.URL(); u.Host != "" {
+		t.Errorf("expected empty URL before first request, got %s", u.String())
+	}
+
+	err := s.On(context.Background())
+	if err != nil {
+		t.Fatalf("Switch.On() error = %v", err)
+	}
+
+	if u := s.URL(); u.String() != ts.URL {
+		t.Errorf("Switch.URL() = %s, want %s", u.String(), ts.URL)
+	}
+
+	err = s.Off(context.Background())
+	if err != nil {
+		t.Fatalf("Switch.Off() error = %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 resolutions, got %d", calls)
+	}
+}
+
+func TestClient_NewSwitchByMAC_unchanged(t *testing.T) {
+	t.Parallel()
+
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	var calls int
+	resolver := mystrom.ResolverFunc(func(context.Context, net.HardwareAddr) (*url.URL, error) {
+		calls++
+		return url.Parse(gone.URL)
+	})
+
+	s := mystrom.NewClient().NewSwitchByMAC(net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x56}, resolver)
+
+	err := s.Toggle(context.Background())
+	if err == nil {
+		t.Fatal("expected Switch.Toggle() to fail")
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 resolutions, got %d", calls)
+	}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	t.Parallel()
+
+	r := mystrom.Registry{}
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x56}
+
+	_, err := r.Resolve(context.Background(), mac)
+	if !errors.Is(err, mystrom.ErrNotFound) {
+		t.Errorf("Registry.Resolve() error = %v, want %v", err, mystrom.ErrNotFound)
+	}
+
+	r.Observe(mystrom.Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 20), Port: 7979},
+		MAC:     mac,
+		Type:    mystrom.DeviceTypeSwitchEU,
+	})
+
+	u, err := r.Resolve(context.Background(), mac)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.String() != "http://192.168.1.20" {
+		t.Errorf("Registry.Resolve() = %s, want http://192.168.1.20", u)
+	}
+}
+
+func TestRegistry_ResolveSince(t *testing.T) {
+	t.Parallel()
+
+	r := mystrom.Registry{}
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x57}
+
+	before := time.Now()
+	r.Observe(mystrom.Device{
+		Address: &net.UDPAddr{IP: net.IPv4(192, 168, 1, 21), Port: 7979},
+		MAC:     mac,
+		Type:    mystrom.DeviceTypeSwitchEU,
+	})
+	after := time.Now()
+
+	u, err := r.ResolveSince(context.Background(), mac, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.String() != "http://192.168.1.21" {
+		t.Errorf("Registry.ResolveSince() = %s, want http://192.168.1.21", u)
+	}
+
+	_, err = r.ResolveSince(context.Background(), mac, after)
+	if !errors.Is(err, mystrom.ErrNotFound) {
+		t.Errorf("Registry.ResolveSince() error = %v, want %v", err, mystrom.ErrNotFound)
+	}
+}
+
+func TestClient_NewSwitchByMAC_ttl(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int
+	}{
+		{name: "no cache", ttl: 0, want: 3},
+		{name: "cached", ttl: time.Hour, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var calls int
+			resolver := mystrom.ResolverFunc(func(context.Context, net.HardwareAddr) (*url.URL, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				calls++
+				return url.Parse(ts.URL)
+			})
+
+			mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x56}
+			s := mystrom.NewClient().NewSwitchByMAC(mac, resolver, mystrom.WithResolveTTL(tt.ttl))
+
+			for i := 0; i < 3; i++ {
+				if err := s.On(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if calls != tt.want {
+				t.Errorf("expected %d resolutions, got %d", tt.want, calls)
+			}
+		})
+	}
+}
+
+func TestClient_NewSwitchByMAC_concurrent(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var calls int
+	started := make(chan struct{})
+	release := make(chan struct{})
+	resolver := mystrom.ResolverFunc(func(context.Context, net.HardwareAddr) (*url.URL, error) {
+		mu.Lock()
+		calls++
+		if calls == 1 {
+			close(started)
+		}
+		mu.Unlock()
+
+		<-release
+		return url.Parse(ts.URL)
+	})
+
+	mac := net.HardwareAddr{0x5c, 0xcf, 0x7f, 0x12, 0x34, 0x58}
+	s := mystrom.NewClient().NewSwitchByMAC(mac, resolver)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.On(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	<-started
+	// the url is available while the resolver is blocked
+	done := make(chan struct{})
+	go func() {
+		_ = s.URL()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Switch.URL() blocked by a pending resolution")
+	}
+
+	// give the other requests time to join the pending resolution
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 1 {
+		t.Errorf("expected 1 resolution, got %d", calls)
+	}
+}
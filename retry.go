@@ -0,0 +1,152 @@
+package mystrom
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy defines how failed requests are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts including the first one.
+	Attempts int
+	// InitialBackoff is the wait time before the first retry, defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait time between two attempts, defaults to 5s.
+	MaxBackoff time.Duration
+	// Multiplier increases the wait time after every attempt, defaults to 2.
+	Multiplier float64
+	// Jitter randomizes the wait time by up to the given fraction, e.g. 0.2 for ±20%.
+	Jitter float64
+	// Retryable decides whether a failed attempt is retried, defaults to IsRetryable.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy is a sensible policy for devices reconnecting to Wi-Fi.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:       4,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetry retries failed requests according to the policy. Requests which
+// are not idempotent, like Switch.Toggle or Switch.PowerCycle, are only
+// retried if they could not be sent at all, so they are never applied twice.
+// Idempotent requests like Switch.Relay, Switch.Report or Switch.Temperature
+// are retried whenever the policy considers the error retryable.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+// IsRetryable reports whether err is a timeout, a refused or reset
+// connection or a temporary status code like 5xx.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return isDialError(err) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(defaultDuration(p.InitialBackoff, 100*time.Millisecond))
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	maxBackoff := float64(defaultDuration(p.MaxBackoff, 5*time.Second))
+
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= multiplier
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1) //nolint:gosec // jitter does not need a secure random source
+	}
+
+	return time.Duration(backoff)
+}
+
+func (p *RetryPolicy) retryable(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if !idempotent(req) {
+		return isDialError(err)
+	}
+
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// doRetry sends req with send until it succeeds or the policy gives up.
+func (p *RetryPolicy) doRetry(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := send(req)
+		if err == nil || attempt >= p.Attempts || !p.retryable(req, err) {
+			return resp, err
+		}
+
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, err
+			}
+
+			body, berr := req.GetBody()
+			if berr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+	}
+}
+
+type idempotencyKey struct{}
+
+// nonIdempotent marks req as not idempotent, it is not retried once it was sent.
+func nonIdempotent(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), idempotencyKey{}, false))
+}
+
+func idempotent(req *http.Request) bool {
+	v, ok := req.Context().Value(idempotencyKey{}).(bool)
+	return !ok || v
+}
+
+func defaultDuration(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,186 @@
+package mystrom_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"thde.io/mystrom"
+)
+
+var testRetryPolicy = mystrom.RetryPolicy{
+	Attempts:       3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	Jitter:         0.5,
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		failures     int32
+		statusCode   int
+		call         func(mystrom.Switch, context.Context) error
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:       "report recovers",
+			failures:   2,
+			statusCode: http.StatusServiceUnavailable,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				_, err := s.Report(ctx)
+				return err
+			},
+			wantAttempts: 3,
+			wantErr:      false,
+		},
+		{
+			name:       "relay gives up",
+			failures:   5,
+			statusCode: http.StatusInternalServerError,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.Relay(ctx, mystrom.RelaySwitchStateOn)
+			},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:       "client errors are not retried",
+			failures:   5,
+			statusCode: http.StatusBadRequest,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				_, err := s.Temperature(ctx)
+				return err
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:       "toggle is never sent twice",
+			failures:   1,
+			statusCode: http.StatusServiceUnavailable,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.Toggle(ctx)
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:       "toggle timer is never sent twice",
+			failures:   1,
+			statusCode: http.StatusServiceUnavailable,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.Timer(ctx, mystrom.SwitchTimerModeToggle, time.Minute)
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:       "on timer recovers",
+			failures:   1,
+			statusCode: http.StatusServiceUnavailable,
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.Timer(ctx, mystrom.SwitchTimerModeOn, time.Minute)
+			},
+			wantAttempts: 2,
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= tt.failures {
+					w.WriteHeader(tt.statusCode)
+					return
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mystrom.NewClient(mystrom.WithRetry(testRetryPolicy))
+
+			err = tt.call(*client.NewSwitch(baseURL), context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestWithRetry_toggleNotSent(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	transport := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	})
+
+	client := mystrom.NewClient(
+		mystrom.WithHTTPClient(&http.Client{Transport: transport}),
+		mystrom.WithRetry(testRetryPolicy),
+	)
+
+	err := client.NewSwitch(&url.URL{Scheme: "http", Host: "192.168.1.20"}).Toggle(context.Background())
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("Switch.Toggle() error = %v, want %v", err, syscall.ECONNREFUSED)
+	}
+
+	// a refused connection is retried as the request has not reached the Switch
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWithRetry_canceled(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := testRetryPolicy
+	policy.Attempts = 100
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = mystrom.NewClient(mystrom.WithRetry(policy)).NewSwitch(baseURL).Report(ctx)
+	if !errors.Is(err, mystrom.ErrStatus) {
+		t.Errorf("Switch.Report() error = %v, want %v", err, mystrom.ErrStatus)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
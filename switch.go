@@ -2,6 +2,8 @@ package mystrom
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -12,6 +14,7 @@ import (
 type Switch struct {
 	baseURL *url.URL
 	client  *Client
+	target  *macTarget
 }
 
 // NewSwitch creates a new Switch instance.
@@ -27,15 +30,88 @@ func NewSwitch(baseURL *url.URL) *Switch {
 	return NewClient().NewSwitch(baseURL)
 }
 
-// Toggle toggles the power state of the Switch.
+// NewSwitchByMAC creates a new Switch instance which resolves its address
+// with the resolver on first use. By default the address is cached and
+// resolved again if the Switch cannot be reached anymore, e.g. after a DHCP
+// lease changed, see WithResolveTTL to limit the cache.
+func (c *Client) NewSwitchByMAC(mac net.HardwareAddr, resolver Resolver, opts ...ResolveOption) *Switch {
+	target := &macTarget{mac: mac, resolver: resolver}
+	for _, opt := range opts {
+		opt(target)
+	}
+
+	return &Switch{
+		client: c,
+		target: target,
+	}
+}
+
+func (s Switch) newRequest(
+	ctx context.Context,
+	method, path string,
+	params url.Values,
+	body interface{},
+) (*http.Request, error) {
+	baseURL := s.baseURL
+	if s.target != nil {
+		var err error
+		baseURL, err = s.target.url(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.client.newRequest(ctx, baseURL, method, path, params, body)
+}
+
+func (s Switch) do(req *http.Request) error {
+	_, err := s.retry(req, func(req *http.Request) (*http.Response, error) {
+		return nil, s.client.doDiscard(req)
+	})
+	return err
+}
+
+func (s Switch) doJSON(req *http.Request, v interface{}) error {
+	_, err := s.retry(req, func(req *http.Request) (*http.Response, error) {
+		return s.client.doJSON(req, v)
+	})
+	return err
+}
+
+// retry sends req once more if the Switch was not reachable and its address changed.
+func (s Switch) retry(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	resp, err := send(req)
+	if err == nil || s.target == nil || !isDialError(err) {
+		return resp, err
+	}
+
+	u, changed, rerr := s.target.refresh(req.Context(), req.URL)
+	if rerr != nil || !changed {
+		return resp, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.URL.Scheme = u.Scheme
+	retry.URL.Host = u.Host
+	retry.Host = ""
+	if req.GetBody != nil {
+		retry.Body, rerr = req.GetBody()
+		if rerr != nil {
+			return resp, err
+		}
+	}
+
+	return send(retry)
+}
+
+// Toggle toggles the power state of the Switch. It is not idempotent.
 func (s Switch) Toggle(ctx context.Context) error {
-	req, err := s.client.newRequest(ctx, s.baseURL, http.MethodGet, "toggle", nil, nil)
+	req, err := s.newRequest(ctx, http.MethodGet, "toggle", nil, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.client.do(req)
-	return err
+	return s.do(nonIdempotent(req))
 }
 
 type RelaySwitchState string
@@ -56,31 +132,61 @@ func (s Switch) Off(ctx context.Context) error {
 }
 
 func (s Switch) Relay(ctx context.Context, state RelaySwitchState) error {
-	req, err := s.client.newRequest(ctx, s.baseURL, http.MethodGet, "relay", url.Values{"state": []string{string(state)}}, nil)
+	req, err := s.newRequest(ctx, http.MethodGet, "relay", url.Values{"state": []string{string(state)}}, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.client.do(req)
-	return err
+	return s.do(req)
 }
 
 // SwitchReport represets the content of a report of the Switch.
+// Fields other than Power and Relay are only provided by newer firmware.
 type SwitchReport struct {
-	Power float64 `json:"power"` // current power consumption in watts
-	Relay bool    `json:"relay"` // state of the Switch, true is on, false is off
+	Power           float64 `json:"power"`             // current power consumption in watts
+	WattsPerSecond  float64 `json:"Ws"`                // average power consumption per second in watts
+	Relay           bool    `json:"relay"`             // state of the Switch, true is on, false is off
+	Temperature     float64 `json:"temperature"`       // temperature in °C
+	BootID          string  `json:"boot_id"`           // changes every time the Switch boots
+	EnergySinceBoot float64 `json:"energy_since_boot"` // energy consumed since boot in watt seconds
+	TimeSinceBoot   int64   `json:"time_since_boot"`   // uptime in seconds
+}
+
+// Uptime returns the time since the Switch booted.
+func (r SwitchReport) Uptime() time.Duration {
+	return time.Duration(r.TimeSinceBoot) * time.Second
+}
+
+// Rebooted returns true if the Switch rebooted since prev was reported,
+// which means EnergySinceBoot and TimeSinceBoot were reset.
+func (r SwitchReport) Rebooted(prev SwitchReport) bool {
+	if r.BootID != "" && prev.BootID != "" {
+		return r.BootID != prev.BootID
+	}
+
+	return prev.TimeSinceBoot > 0 && r.TimeSinceBoot < prev.TimeSinceBoot
 }
 
 // Report returns a report of the current statut of the Switch.
 func (s Switch) Report(ctx context.Context) (*SwitchReport, error) {
+	return s.report(ctx, "report")
+}
+
+// ReportV1 returns a report of the current statut of the Switch using the
+// /api/v1/report endpoint of newer firmware including energy and boot info.
+func (s Switch) ReportV1(ctx context.Context) (*SwitchReport, error) {
+	return s.report(ctx, "api/v1/report")
+}
+
+func (s Switch) report(ctx context.Context, path string) (*SwitchReport, error) {
 	report := SwitchReport{}
 
-	req, err := s.client.newRequest(ctx, s.baseURL, http.MethodGet, "report", nil, nil)
+	req, err := s.newRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
 		return &report, err
 	}
 
-	_, err = s.client.doJSON(req, &report)
+	err = s.doJSON(req, &report)
 	return &report, err
 }
 
@@ -96,21 +202,24 @@ type SwitchTemperature struct {
 func (s Switch) Temperature(ctx context.Context) (*SwitchTemperature, error) {
 	temp := SwitchTemperature{}
 
-	req, err := s.client.newRequest(ctx, s.baseURL, http.MethodGet, "api/v1/temperature", nil, nil)
+	req, err := s.newRequest(ctx, http.MethodGet, "api/v1/temperature", nil, nil)
 	if err != nil {
 		return &temp, err
 	}
 
-	_, err = s.client.doJSON(req, &temp)
+	err = s.doJSON(req, &temp)
 	return &temp, err
 }
 
 // PowerCycle turns the switch off, waits for a specified amount of time (max 1h), then starts it again.
-// The switch has to be turned on in order for this call to work.
+// The switch has to be turned on in order for this call to work. It is not idempotent.
 func (s Switch) PowerCycle(ctx context.Context, wait time.Duration) error {
-	req, err := s.client.newRequest(
+	if wait < 0 || wait > time.Hour {
+		return fmt.Errorf("%w: %s", ErrPowerCycleOutOfRange, wait)
+	}
+
+	req, err := s.newRequest(
 		ctx,
-		s.baseURL,
 		http.MethodGet,
 		"power_cycle",
 		url.Values{"time": []string{strconv.Itoa(int(wait.Seconds()))}},
@@ -120,8 +229,7 @@ func (s Switch) PowerCycle(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
-	_, err = s.client.do(req)
-	return err
+	return s.do(nonIdempotent(req))
 }
 
 type SwitchTimerMode string
@@ -133,11 +241,25 @@ const (
 	SwitchTimerModeNone   SwitchTimerMode = "none"
 )
 
+// maxTimer is the longest time accepted by Switch.Timer.
+const maxTimer = 24 * time.Hour
+
 // Timer sets the relay state for a given time, after the time has elapsed the state of the relay is reversed.
+// The time must be between 0 and 24 hours, SwitchTimerModeNone cancels a running timer and ignores it.
+// It is not idempotent for SwitchTimerModeToggle.
 func (s Switch) Timer(ctx context.Context, mode SwitchTimerMode, time time.Duration) error {
-	req, err := s.client.newRequest(
+	switch mode {
+	case SwitchTimerModeOn, SwitchTimerModeOff, SwitchTimerModeToggle:
+		if time < 0 || time > maxTimer {
+			return fmt.Errorf("%w: %s", ErrTimerOutOfRange, time)
+		}
+	case SwitchTimerModeNone:
+	default:
+		return fmt.Errorf("timer mode '%s' is not defined", mode)
+	}
+
+	req, err := s.newRequest(
 		ctx,
-		s.baseURL,
 		http.MethodPost,
 		"timer",
 		url.Values{
@@ -150,10 +272,96 @@ func (s Switch) Timer(ctx context.Context, mode SwitchTimerMode, time time.Durat
 		return err
 	}
 
-	_, err = s.client.do(req)
-	return err
+	// toggling immediately flips the relay, sending it again would flip it back
+	if mode == SwitchTimerModeToggle {
+		req = nonIdempotent(req)
+	}
+
+	return s.do(req)
+}
+
+// SwitchSettings represents the settings of the Switch.
+type SwitchSettings struct {
+	Name      string `json:"name"`       // name of the Switch shown in the app
+	LED       bool   `json:"led_enable"` // true if the status LED is enabled
+	Restore   bool   `json:"restore"`    // true if the relay state is restored after a power loss
+	PanelLock bool   `json:"panel_lock"` // true if the button on the Switch is disabled
+}
+
+// SwitchSettingsUpdate is a partial update of SwitchSettings, only fields
+// which are not nil are sent to the Switch.
+type SwitchSettingsUpdate struct {
+	Name      *string `json:"name,omitempty"`
+	LED       *bool   `json:"led_enable,omitempty"`
+	Restore   *bool   `json:"restore,omitempty"`
+	PanelLock *bool   `json:"panel_lock,omitempty"`
+}
+
+// Empty returns true if the update does not change any setting.
+func (u SwitchSettingsUpdate) Empty() bool {
+	return u == SwitchSettingsUpdate{}
+}
+
+// Diff returns an update containing the fields of s which differ from current.
+func (s SwitchSettings) Diff(current SwitchSettings) SwitchSettingsUpdate {
+	u := SwitchSettingsUpdate{}
+	if s.Name != current.Name {
+		u.Name = &s.Name
+	}
+	if s.LED != current.LED {
+		u.LED = &s.LED
+	}
+	if s.Restore != current.Restore {
+		u.Restore = &s.Restore
+	}
+	if s.PanelLock != current.PanelLock {
+		u.PanelLock = &s.PanelLock
+	}
+	return u
 }
 
+// Settings returns the current settings of the Switch.
+func (s Switch) Settings(ctx context.Context) (*SwitchSettings, error) {
+	settings := SwitchSettings{}
+
+	req, err := s.newRequest(ctx, http.MethodGet, "api/v1/settings", nil, nil)
+	if err != nil {
+		return &settings, err
+	}
+
+	err = s.doJSON(req, &settings)
+	return &settings, err
+}
+
+// SetSettings replaces all settings of the Switch.
+func (s Switch) SetSettings(ctx context.Context, settings SwitchSettings) error {
+	return s.setSettings(ctx, settings)
+}
+
+// UpdateSettings changes only the settings set in update. An empty update
+// does not send a request.
+func (s Switch) UpdateSettings(ctx context.Context, update SwitchSettingsUpdate) error {
+	if update.Empty() {
+		return nil
+	}
+	return s.setSettings(ctx, update)
+}
+
+func (s Switch) setSettings(ctx context.Context, body interface{}) error {
+	req, err := s.newRequest(ctx, http.MethodPost, "api/v1/settings", nil, body)
+	if err != nil {
+		return err
+	}
+
+	return s.do(req)
+}
+
+// URL returns the base URL of the Switch. For a Switch created by
+// NewSwitchByMAC it is the last resolved address, which is empty until
+// the first request.
 func (s Switch) URL() url.URL {
+	if s.target != nil {
+		return s.target.current()
+	}
 	return *s.baseURL
 }
@@ -2,11 +2,14 @@ package mystrom_test
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -188,6 +191,23 @@ func TestSwitch_Report(t *testing.T) {
 			want:    &mystrom.SwitchReport{Power: 100, Relay: true},
 			wantErr: false,
 		},
+		{
+			name: "success newer firmware",
+			args: args{
+				body:       []byte(`{"power": 12.5, "Ws": 12.4, "relay": true, "temperature": 24.1, "boot_id": "4B0C1A2E", "energy_since_boot": 3600, "time_since_boot": 300}`),
+				statusCode: http.StatusOK,
+			},
+			want: &mystrom.SwitchReport{
+				Power:           12.5,
+				WattsPerSecond:  12.4,
+				Relay:           true,
+				Temperature:     24.1,
+				BootID:          "4B0C1A2E",
+				EnergySinceBoot: 3600,
+				TimeSinceBoot:   300,
+			},
+			wantErr: false,
+		},
 		{
 			name: "error",
 			args: args{
@@ -233,6 +253,56 @@ func TestSwitch_Report(t *testing.T) {
 	}
 }
 
+func TestSwitch_ReportV1(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/report" {
+			t.Errorf("expected /api/v1/report path, got %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{"power": 1.5, "Ws": 1.4, "relay": true, "temperature": 21, "boot_id": "4B0C1A2E", "energy_since_boot": 60, "time_since_boot": 40}`))
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := mystrom.NewClient().NewSwitch(baseURL).ReportV1(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.BootID != "4B0C1A2E" || report.Uptime() != 40*time.Second {
+		t.Errorf("Switch.ReportV1() = %v", report)
+	}
+}
+
+func TestSwitchReport_Rebooted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		prev mystrom.SwitchReport
+		cur  mystrom.SwitchReport
+		want bool
+	}{
+		{"same boot id", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 10}, mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 20}, false},
+		{"changed boot id", mystrom.SwitchReport{BootID: "a", TimeSinceBoot: 10}, mystrom.SwitchReport{BootID: "b", TimeSinceBoot: 20}, true},
+		{"uptime reset", mystrom.SwitchReport{TimeSinceBoot: 100}, mystrom.SwitchReport{TimeSinceBoot: 5}, true},
+		{"old firmware", mystrom.SwitchReport{Power: 10}, mystrom.SwitchReport{Power: 20}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cur.Rebooted(tt.prev); got != tt.want {
+				t.Errorf("SwitchReport.Rebooted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSwitch_Temperature(t *testing.T) {
 	t.Parallel()
 
@@ -370,6 +440,25 @@ func TestSwitch_PowerCycle(t *testing.T) {
 	}
 }
 
+func TestSwitch_PowerCycle_outOfRange(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mystrom.NewSwitch(baseURL).PowerCycle(context.Background(), 2*time.Hour)
+	if !errors.Is(err, mystrom.ErrPowerCycleOutOfRange) || errors.Is(err, mystrom.ErrPowerCycleRelayOff) {
+		t.Errorf("Switch.PowerCycle() error = %v, want %v", err, mystrom.ErrPowerCycleOutOfRange)
+	}
+}
+
 func TestSwitch_Timer(t *testing.T) {
 	t.Parallel()
 
@@ -467,3 +556,150 @@ func TestSwitch_Timer(t *testing.T) {
 		})
 	}
 }
+
+func TestSwitch_Timer_invalid(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mystrom.NewSwitch(baseURL)
+
+	for _, d := range []time.Duration{-time.Second, 25 * time.Hour} {
+		err = s.Timer(context.Background(), mystrom.SwitchTimerModeOn, d)
+		if !errors.Is(err, mystrom.ErrTimerOutOfRange) {
+			t.Errorf("Switch.Timer(%s) error = %v, want %v", d, err, mystrom.ErrTimerOutOfRange)
+		}
+	}
+
+	err = s.Timer(context.Background(), "blink", time.Second)
+	if err == nil || errors.Is(err, mystrom.ErrTimerOutOfRange) {
+		t.Errorf("Switch.Timer() error = %v, want invalid mode", err)
+	}
+}
+
+func TestSwitch_Settings(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET method, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/api/v1/settings" {
+			t.Errorf("expected /api/v1/settings path, got %s", r.URL.Path)
+		}
+
+		_, _ = w.Write([]byte(`{"name": "Kitchen", "led_enable": true, "restore": true, "panel_lock": false}`))
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := mystrom.NewSwitch(baseURL).Settings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &mystrom.SwitchSettings{Name: "Kitchen", LED: true, Restore: true}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("Switch.Settings() = %v, want %v", settings, want)
+	}
+}
+
+func TestSwitch_SetSettings(t *testing.T) {
+	t.Parallel()
+
+	restore := true
+	tests := []struct {
+		name string
+		call func(mystrom.Switch, context.Context) error
+		want string // request body, empty if no request is expected
+	}{
+		{
+			name: "set",
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.SetSettings(ctx, mystrom.SwitchSettings{Name: "Kitchen", LED: true})
+			},
+			want: `{"name":"Kitchen","led_enable":true,"restore":false,"panel_lock":false}`,
+		},
+		{
+			name: "update",
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.UpdateSettings(ctx, mystrom.SwitchSettingsUpdate{Restore: &restore})
+			},
+			want: `{"restore":true}`,
+		},
+		{
+			name: "empty update",
+			call: func(s mystrom.Switch, ctx context.Context) error {
+				return s.UpdateSettings(ctx, mystrom.SwitchSettingsUpdate{})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.want == "" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+				}
+
+				if r.Method != http.MethodPost {
+					t.Errorf("expected POST method, got %s", r.Method)
+				}
+
+				if r.URL.Path != "/api/v1/settings" {
+					t.Errorf("expected /api/v1/settings path, got %s", r.URL.Path)
+				}
+
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := strings.TrimSpace(string(body)); got != tt.want {
+					t.Errorf("expected body %s, got %s", tt.want, got)
+				}
+			}))
+			defer ts.Close()
+
+			baseURL, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.call(*mystrom.NewSwitch(baseURL), context.Background())
+			if err != nil {
+				t.Errorf("Switch %s error = %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestSwitchSettings_Diff(t *testing.T) {
+	t.Parallel()
+
+	current := mystrom.SwitchSettings{Name: "Kitchen", LED: true}
+	policy := current
+	policy.Restore = true
+
+	update := policy.Diff(current)
+	if update.Name != nil || update.LED != nil || update.PanelLock != nil {
+		t.Errorf("SwitchSettings.Diff() = %+v, want only restore", update)
+	}
+	if update.Restore == nil || !*update.Restore {
+		t.Errorf("SwitchSettings.Diff() restore = %v, want true", update.Restore)
+	}
+
+	if !current.Diff(current).Empty() {
+		t.Error("SwitchSettings.Diff() expected empty update for equal settings")
+	}
+}